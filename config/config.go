@@ -0,0 +1,66 @@
+// Package config has the configuration of a matte app,
+// the MatteApp func of your project returns it to configure the generated app,
+// a matte.toml at the root of the project can be used instead, its keys are the toml tags of Config.
+package config
+
+// router the generated app uses
+const HTTPRouter = "httprouter"
+
+// Config of the generated app
+type Config struct {
+	// address the http server listens on, ex: ":8000"
+	Addr string `toml:"addr" json:"addr"`
+	// router of the generated app, only HTTPRouter is supported for now
+	Framework string `toml:"framework" json:"framework"`
+	// dir the app is generated into, relative to the root of the project
+	OutputDir string `toml:"output_dir" json:"output_dir"`
+	// dirs of the project, relative to its root, which are not scanned for handlers
+	Exclude []string `toml:"exclude" json:"exclude"`
+	Docs    Docs     `toml:"docs" json:"docs"`
+	// path the title and version of the app are served at, empty disables it
+	VersionPath string  `toml:"version_path" json:"version_path"`
+	Outputs     Outputs `toml:"outputs" json:"outputs"`
+}
+
+// Outputs are the files generated into the output dir besides the app
+type Outputs struct {
+	// openapi document as json and yaml, the docs serve it so it cannot be disabled while they are enabled
+	OpenAPI bool `toml:"openapi" json:"openapi"`
+	// go client having a method for every handler, in the client dir
+	GoClient bool `toml:"go_client" json:"go_client"`
+	// typescript client having a function for every handler, client.ts
+	TypeScriptClient bool `toml:"typescript_client" json:"typescript_client"`
+}
+
+// Docs configures the openapi document and the api explorer served by the app
+type Docs struct {
+	// whether the docs are served at all
+	Enabled bool `toml:"enabled" json:"enabled"`
+	// path the openapi document is served at
+	SpecPath string `toml:"spec_path" json:"spec_path"`
+	// path the api explorer is served at
+	UIPath string `toml:"ui_path" json:"ui_path"`
+	// values of the MATTE_ENV environment variable the docs are served in, empty means every environment
+	Environments []string `toml:"environments" json:"environments"`
+}
+
+// Default returns the config used for anything your MatteApp func or matte.toml leaves out
+func Default() Config {
+	return Config{
+		Addr:        ":8000",
+		Framework:   HTTPRouter,
+		OutputDir:   "matte",
+		VersionPath: "/version",
+		Docs: Docs{
+			Enabled:      true,
+			SpecPath:     "/openapi.json",
+			UIPath:       "/docs",
+			Environments: []string{"development", "staging"},
+		},
+		Outputs: Outputs{
+			OpenAPI:          true,
+			GoClient:         true,
+			TypeScriptClient: true,
+		},
+	}
+}
@@ -5,14 +5,12 @@ go 1.20
 require golang.org/x/mod v0.14.0
 
 require (
-	github.com/BurntSushi/toml v1.3.2 // indirect
 	github.com/KyleBanks/depth v1.2.1 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-openapi/jsonpointer v0.20.0 // indirect
 	github.com/go-openapi/jsonreference v0.20.2 // indirect
 	github.com/go-openapi/swag v0.22.4 // indirect
 	github.com/go-yaml/yaml v2.1.0+incompatible // indirect
-	github.com/invopop/yaml v0.2.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/mohae/deepcopy v0.0.0-20170929034955-c48cc78d4826 // indirect
@@ -23,8 +21,10 @@ require (
 )
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/getkin/kin-openapi v0.122.0
 	github.com/go-openapi/spec v0.20.11
+	github.com/invopop/yaml v0.2.0
 	github.com/julienschmidt/httprouter v1.3.0
 	github.com/ondbyte/turbo_flag v0.1.9
 	github.com/rogpeppe/go-internal v1.12.0
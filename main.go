@@ -1,32 +1,60 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"go/token"
 	"log"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	m "github.com/ondbyte/matte/v1"
+	"github.com/ondbyte/matte/web"
 	flag "github.com/ondbyte/turbo_flag"
 )
 
-var matte *m.Matte
-
 func main() {
 	usage := `
 matte: a microservice developement tooling for go
 available sub commands are.
 (run <sub-command> -h for more on it)
-1. build`
+1. configure: writes a config.matte.go configuring your app, or use a matte.toml instead
+2. new: scaffolds a service, or a handler of a pkg of the project
+3. config: prints the config the project is built with
+4. build
+5. routes: lists every route of the project along with its handler and params
+6. import-openapi: writes decorated handler stubs for every operation of a openapi document
+7. run: builds and starts the app, rebuilds and restarts it on every change
+8. api-diff: compares the api of the project against a snapshot, exits non-zero on breaking changes`
 	flag.MainCmd("matte", usage, flag.PanicOnError, os.Args[1:], matteCmd)
 
 }
 
+// turbo_flag takes every arg having a '-' for a flag, so the sub commands having one in their name are run by matteCmd itself
+var dashedCmds = map[string]func(cmd flag.CMD, args []string){
+	"import-openapi": importOpenAPICmd,
+	"api-diff":       apiDiffCmd,
+}
+
 func matteCmd(cmd flag.CMD, args []string) {
+	if len(args) > 0 && dashedCmds[args[0]] != nil {
+		dashedCmds[args[0]](flag.NewFlagSet(args[0], flag.PanicOnError), args[1:])
+		return
+	}
 	cmd.SubCmd("configure", `intialize your configuration for your app, this adds a config.matte.go to you root project, 
 	where you can configure different frameworks and others configs`, configureCmd)
+	cmd.SubCmd("new", "scaffolds a service or a handler, ex: new service github.com/acme/users, new handler users GET /users/:id", newCmd)
+	cmd.SubCmd("config", "shows the config of your project, ex: config print", configCmd)
 	cmd.SubCmd("build", "build your matte project", buildCmd)
+	cmd.SubCmd("routes", "lists every route of your project along with its handler, params and position", routesCmd)
+	cmd.SubCmd("run", "builds and starts your app, then rebuilds and restarts it whenever a go file of the project changes", runCmd)
 	cmd.SubCmd("chinmaya", "wife: will something happen when i enter my name? can you make it work?", chinmayaCmd)
 	err := cmd.Parse(args)
 	if err != nil {
@@ -35,23 +63,167 @@ func matteCmd(cmd flag.CMD, args []string) {
 }
 
 func configureCmd(cmd flag.CMD, args []string) {
-	wd, err := os.Getwd()
+	help := false
+	yes := false
+	workingDir := ""
+	options := m.ConfigureOptions{}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project you need to configure", flag.Alias("d"))
+	cmd.BoolVar(&options.Force, "force", false, "overwrites "+m.ConfigFile+" if it exists", flag.Alias("f"))
+	cmd.BoolVar(&yes, "yes", false, "asks nothing, the defaults are used for everything not set by a flag", flag.Alias("y"))
+	cmd.StringVar(&options.Title, "title", "", "title of the api, the name of the module by default")
+	cmd.StringVar(&options.Version, "version", "", "version of the api, 0.0.0 by default")
+	cmd.StringVar(&options.Addr, "addr", "", "address the app listens on, :8000 by default")
+	err := cmd.Parse(args)
+	if err != nil {
+		panic(err)
+	}
+	if help {
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	// questions are asked only when someone is there to answer them
+	if info, err := os.Stdin.Stat(); !yes && err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		options.In = os.Stdin
+		options.Out = os.Stdout
+	}
+	err = m.Configure(workingDir, options)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %v, edit it to configure your app\n", filepath.Join(workingDir, m.ConfigFile))
+}
+
+func newCmd(cmd flag.CMD, args []string) {
+	cmd.SubCmd("service", "creates a service having a go.mod, a "+m.ConfigFile+" and a sample handler, ex: new service github.com/acme/users", newServiceCmd)
+	cmd.SubCmd("handler", "adds a handler stub and its test to a pkg of the project, ex: new handler users GET /users/:id", newHandlerCmd)
+	err := cmd.Parse(args)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newServiceCmd(cmd flag.CMD, args []string) {
+	help := false
+	modulePath := ""
+	dir := ""
+	options := m.NewServiceOptions{}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		modulePath, args = args[0], args[1:]
+	}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&dir, "dir", "", "dir the service is created in, the last element of the module path by default", flag.Alias("d"))
+	cmd.StringVar(&options.MatteVersion, "matte-version", "", "version of matte required by the service, the version of this matte by default, it must be set if this matte has no version")
+	cmd.StringVar(&options.MatteReplace, "matte-replace", "", "dir of a local matte the service requires using a replace directive")
+	err := cmd.Parse(args)
+	if err != nil {
+		panic(err)
+	}
+	if help || modulePath == "" {
+		fmt.Println("usage: matte new service <module path> [flags]")
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	if dir == "" {
+		dir = path.Base(modulePath)
+	}
+	err = m.NewService(dir, modulePath, options)
 	if err != nil {
-		panic("unable to get working directory")
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	err = matte.Configure(wd)
+	fmt.Printf("created %v, run it using\n\tcd %v && go mod tidy && matte run\n", modulePath, dir)
+}
+
+func newHandlerCmd(cmd flag.CMD, args []string) {
+	help := false
+	workingDir := ""
+	positional := []string{}
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = append(positional, args[0]), args[1:]
+	}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project", flag.Alias("d"))
+	err := cmd.Parse(args)
+	if err != nil {
+		panic(err)
+	}
+	if help || len(positional) != 3 {
+		fmt.Println("usage: matte new handler <pkg dir> <method> <path> [flags], ex: matte new handler users GET /users/:id")
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	files, err := m.NewHandler(workingDir, positional[0], positional[1], positional[2])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, file := range files {
+		fmt.Println("wrote", file)
+	}
+}
+
+func configCmd(cmd flag.CMD, args []string) {
+	cmd.SubCmd("print", "prints the config your project is built with, ie: the defaults overridden by its "+m.ConfigTOMLFile+" or "+m.ConfigFile, configPrintCmd)
+	err := cmd.Parse(args)
 	if err != nil {
 		panic(err)
 	}
 }
 
+func configPrintCmd(cmd flag.CMD, args []string) {
+	help := false
+	asJSON := false
+	workingDir := ""
+	env := ""
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project", flag.Alias("d"))
+	cmd.StringVar(&env, "env", web.Env(), "env whose overrides of "+m.ConfigTOMLFile+" are applied, "+web.EnvVar+" or production by default", flag.Alias("e"))
+	cmd.BoolVar(&asJSON, "json", false, "prints the config as json", flag.Alias("j"))
+	err := cmd.Parse(args)
+	if err != nil {
+		panic(err)
+	}
+	if help {
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	c, source, err := m.EffectiveConfig(token.NewFileSet(), workingDir, env)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if asJSON {
+		jsonBytes, _ := json.MarshalIndent(c, "", "  ")
+		fmt.Println(string(jsonBytes))
+		return
+	}
+	if source == "" {
+		source = "the defaults"
+	}
+	fmt.Printf("# config of %v for %v=%q, read from %v\n", workingDir, web.EnvVar, env, source)
+	err = toml.NewEncoder(os.Stdout).Encode(c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
 func buildCmd(cmd flag.CMD, args []string) {
 	help := false
 	noBuild := false
 	workingDir := ""
 	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
-	cmd.BoolVar(&noBuild, "no-build", false, "only runs finalize, this is a dev flag, possible to inspect src outputted in app.go ", flag.Alias("n"))
+	cmd.BoolVar(&noBuild, "no-build", false, "only generates the src, this is a dev flag, possible to inspect src outputted in app.go ", flag.Alias("n"))
 	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project you need to build", flag.Alias("d"))
+	options := m.BuildOptions{Stdout: os.Stdout, Stderr: os.Stderr}
+	cmd.StringVar(&options.Output, "output", "", "path of the binary, by default the name of the module in the project dir", flag.Alias("o"))
+	cmd.StringVar(&options.LDFlags, "ldflags", "", "passed to go build as -ldflags")
+	cmd.StringVar(&options.Tags, "tags", "", "comma separated build tags passed to go build", flag.Alias("t"))
+	cmd.BoolVar(&options.Race, "race", false, "builds the binary with the race detector")
+	cmd.StringVar(&options.GOOS, "goos", "", "target os of the binary, GOOS of the environment by default")
+	cmd.StringVar(&options.GOARCH, "goarch", "", "target arch of the binary, GOARCH of the environment by default")
 	err := cmd.Parse(args)
 	if err != nil {
 		panic(err)
@@ -61,47 +233,161 @@ func buildCmd(cmd flag.CMD, args []string) {
 		return
 	}
 
-	fileset := token.NewFileSet()
-	if err != nil {
-		fmt.Println("unable to get working dir")
-		panic(err)
+	if noBuild {
+		err = m.Build(token.NewFileSet(), workingDir)
+	} else {
+		err = m.BuildProject(token.NewFileSet(), workingDir, options)
 	}
-	err = os.Chdir(workingDir)
 	if err != nil {
-		panic(fmt.Errorf("unable change working dir to %v", workingDir))
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	matte, err = m.Build(fileset, frameworks.Frameworks{
-		frameworks.NewHttp(),
-	})
+}
+
+func routesCmd(cmd flag.CMD, args []string) {
+	help := false
+	asJSON := false
+	workingDir := ""
+	filter := m.RouteFilter{}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project", flag.Alias("d"))
+	cmd.BoolVar(&asJSON, "json", false, "prints the routes as json", flag.Alias("j"))
+	cmd.StringVar(&filter.Method, "method", "", "lists only the routes of the method, ex: GET", flag.Alias("m"))
+	cmd.StringVar(&filter.PathPrefix, "path", "", "lists only the routes whose path begins with its segments, ex: /users", flag.Alias("p"))
+	cmd.StringVar(&filter.Pkg, "pkg", "", "lists only the routes whose handler is in the pkg, by name or import path")
+	err := cmd.Parse(args)
 	if err != nil {
 		panic(err)
 	}
+	if help {
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	routes, err := m.ListRoutes(token.NewFileSet(), workingDir, filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if asJSON {
+		jsonBytes, _ := json.MarshalIndent(routes, "", "  ")
+		fmt.Println(string(jsonBytes))
+		return
+	}
+	m.WriteRoutesTable(os.Stdout, routes)
+}
 
-	err = matte.LoadProject()
+func runCmd(cmd flag.CMD, args []string) {
+	help := false
+	workingDir := ""
+	options := m.RunOptions{BuildOptions: m.BuildOptions{Stdout: os.Stdout, Stderr: os.Stderr}}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project you need to run", flag.Alias("d"))
+	cmd.StringVar(&options.LDFlags, "ldflags", "", "passed to go build as -ldflags")
+	cmd.StringVar(&options.Tags, "tags", "", "comma separated build tags passed to go build", flag.Alias("t"))
+	cmd.BoolVar(&options.Race, "race", false, "builds the app with the race detector")
+	cmd.DurationVar(&options.Interval, "interval", 500*time.Millisecond, "how often the project is checked for changes", flag.Alias("i"))
+	err := cmd.Parse(args)
 	if err != nil {
 		panic(err)
 	}
-	err = matte.ParseGeneralAPIInfo()
+	if help {
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = m.Run(ctx, workingDir, options)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
+}
 
-	err = matte.ProcessProject()
+// matte import-openapi <spec> [flags]
+func importOpenAPICmd(cmd flag.CMD, args []string) {
+	help := false
+	force := false
+	pkgDir := ""
+	spec := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		spec, args = args[0], args[1:]
+	}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&spec, "spec", spec, "path of the openapi document, json or yaml", flag.Alias("s"))
+	cmd.BoolVar(&force, "force", false, "overwrite the stubs already in the pkg dir", flag.Alias("f"))
+	cmd.StringVar(&pkgDir, "pkg", "./handlers", "dir of the pkg the stubs are written to", flag.Alias("p"))
+	err := cmd.Parse(args)
 	if err != nil {
 		panic(err)
 	}
+	if help || spec == "" {
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	warnings, err := m.ImportOpenAPI(spec, pkgDir, force)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+}
 
-	err = matte.Finalize()
+// matte api-diff <base openapi document> [flags]
+func apiDiffCmd(cmd flag.CMD, args []string) {
+	help := false
+	asJSON := false
+	workingDir := ""
+	base := ""
+	write := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		base, args = args[0], args[1:]
+	}
+	cmd.BoolVar(&help, "help", false, "prints this", flag.Alias("h"))
+	cmd.StringVar(&base, "base", base, "openapi document (json or yaml) or snapshot to compare against", flag.Alias("b"))
+	cmd.StringVar(&workingDir, "dir", "./", "root directory of the project", flag.Alias("d"))
+	cmd.StringVar(&write, "write", "", "writes the current api as a snapshot to this file instead of comparing", flag.Alias("w"))
+	cmd.BoolVar(&asJSON, "json", false, "prints the changes as json", flag.Alias("j"))
+	err := cmd.Parse(args)
 	if err != nil {
 		panic(err)
 	}
-	if !noBuild {
-		err = matte.Build()
+	if help || (base == "" && write == "") {
+		log.Println(cmd.GetDefaultUsage())
+		return
+	}
+	if write != "" {
+		err = m.WriteAPISnapshot(token.NewFileSet(), workingDir, write)
 		if err != nil {
-			panic(err)
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
+	}
+	changes, err := m.APIDiff(token.NewFileSet(), workingDir, base)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	switch {
+	case asJSON:
+		if changes == nil {
+			changes = m.APIChanges{}
+		}
+		jsonBytes, _ := json.MarshalIndent(changes, "", "  ")
+		fmt.Println(string(jsonBytes))
+	case len(changes) == 0:
+		fmt.Println("no api changes")
+	default:
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+	}
+	if changes.Breaking() {
+		fmt.Fprintln(os.Stderr, "api has breaking changes")
+		os.Exit(1)
 	}
-
 }
 
 func chinmayaCmd(cmd flag.CMD, args []string) {
@@ -0,0 +1,17 @@
+// Package scaffold has the templates the app is generated from, they are compilable go having placeholders,
+// ie: `var _ = "xPlaceHolder"`, which are replaced by the src generated for the project.
+// main.go runs every server of the app, every other template is a server started by its RunXServer func.
+// the service dir is the template of a new service, its pkgs are the ones every new service starts with
+package scaffold
+
+import "embed"
+
+// Files are the templates, embedded so matte generates the app without its source around
+//
+//go:embed main.go http.go
+var Files embed.FS
+
+// Service is the template of a new service, the pkg at its root is renamed to the one of the service
+//
+//go:embed service
+var Service embed.FS
@@ -1,39 +1,37 @@
-package main
+package scaffold
 
 import (
-	"context"
 	"errors"
-	"net"
+	"log"
 	"net/http"
+
+	"github.com/julienschmidt/httprouter"
 )
 
-func RunHttpServer() (chan error, ShutDowner) {
-	ctx, cancel := context.WithCancel(context.Background())
-	handler := http.NewServeMux()
+// replaced with the package level declarations of the generated src, ex: the embedded openapi document
+var _ = "declsPlaceHolder"
+
+// RunHTTPServer starts the http server serving the handlers of the app
+func RunHTTPServer() (chan error, ShutDowner) {
+	router := httprouter.New()
+	// what the server is logged as, set to the title and version of the app by the handlers
+	banner := "http server"
 
+	// replaced with the handlers of the app, along with its docs and version
 	var _ = "handlersPlaceHolder"
 
 	addr := DefaultPort
+	// replaced with the address the app listens on if the config sets one other than DefaultPort
 	var _ = "portPlaceHolder"
 
-	server := http.Server{
-		Addr:    addr,
-		Handler: handler,
-		BaseContext: func(l net.Listener) context.Context {
-			return ctx
-		},
-	}
-
+	server := &http.Server{Addr: addr, Handler: router}
 	errChan := make(chan error, 1)
-
 	go func() {
+		log.Printf("%v listening on %v", banner, addr)
 		err := server.ListenAndServe()
 		if !errors.Is(err, http.ErrServerClosed) {
 			errChan <- err
 		}
-		cancel()
 	}()
-	return errChan, func() error {
-		return server.Shutdown(ctx)
-	}
+	return errChan, server.Shutdown
 }
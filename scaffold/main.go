@@ -1,69 +1,59 @@
-package main
+package scaffold
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+// replaced by nothing, the imports of the generated src are added to the imports of the app
 var _ = "importsPlaceHolder"
 
-type ShutDowner func() error
+// ShutDowner shuts a server down gracefully, requests in flight are given until ctx is done to finish
+type ShutDowner func(ctx context.Context) error
+
+// ServerRunner starts a server, the returned chan receives the error it stops with
 type ServerRunner func() (chan error, ShutDowner)
 
 var (
 	DefaultPort = ":8000"
 
-	// all the servers
+	// all the servers of the app
 	ServerRunners = []ServerRunner{}
-	// all the server shut downers
-	ShutDowners = []ShutDowner{}
 )
 
-func osSignal() chan os.Signal {
-	signalChannel := make(chan os.Signal, 1)
-	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM, os.Kill, syscall.SIGINT)
-	return signalChannel
-}
-
 func main() {
-	// place holder should be replaced with `Runners = append(Runners, ...)`
+	// replaced with `ServerRunners = append(ServerRunners, ...)` for every server of the app
 	var _ = "serverRunnersPlaceHolder"
 
-	errChan := make(chan error)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errChan := make(chan error, len(ServerRunners))
+	shutDowners := []ShutDowner{}
 	for _, serverRunner := range ServerRunners {
 		serverErr, shutDowner := serverRunner()
-		ShutDowners = append(ShutDowners, shutDowner)
+		shutDowners = append(shutDowners, shutDowner)
 		go func() {
 			errChan <- <-serverErr
 		}()
 	}
-	shutDown := func() error {
-		errS := ""
-		for _, shutDown := range ShutDowners {
-			err := shutDown()
-			if err != nil {
-				errS += errS + "\n"
-			}
-		}
-		if errS != "" {
-			return fmt.Errorf(errS)
-		}
-		return nil
-	}
+	var err error
 	select {
-	case err := <-errChan:
-		{
-			if err != nil {
-				panic(err)
-			}
-		}
-	case sig := <-osSignal():
-		{
-			fmt.Printf("signal: %v", sig)
-			fmt.Println("shutting down")
-			shutDown()
-		}
+	case err = <-errChan:
+	case <-ctx.Done():
+		log.Println("shutting down")
+	}
+	// requests in flight are given a while to finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, shutDown := range shutDowners {
+		err = errors.Join(err, shutDown(shutdownCtx))
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 }
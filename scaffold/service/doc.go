@@ -0,0 +1,2 @@
+// Package service is a service, matte generates its app from the handlers of its pkgs, run it using matte run
+package service
@@ -0,0 +1,9 @@
+// Package hello is a sample of a pkg having handlers
+package hello
+
+// Greet greets the one named in the path
+//
+// @path("GET","/hello/:name")
+func Greet(name string) string {
+	return "hello " + name
+}
@@ -0,0 +1,14 @@
+package hello_test
+
+import (
+	"testing"
+
+	"github.com/ondbyte/matte/scaffold/service/hello"
+)
+
+func TestGreet(t *testing.T) {
+	greeting := hello.Greet("gopher")
+	if greeting != "hello gopher" {
+		t.Fatalf("expected hello gopher but got %v", greeting)
+	}
+}
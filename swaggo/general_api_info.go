@@ -0,0 +1,340 @@
+package swaggo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	hostAttr                   = "@host"
+	basePathAttr               = "@basepath"
+	schemesAttr                = "@schemes"
+	tagNameAttr                = "@tag.name"
+	tagDescriptionAttr         = "@tag.description"
+	tagDescriptionMarkdownAttr = "@tag.description.markdown"
+	tagDocsURLAttr             = "@tag.docs.url"
+	tagDocsDescriptionAttr     = "@tag.docs.description"
+)
+
+// IsGeneralAPIComment reports whether the comment lines describe the api rather than an operation of it
+func IsGeneralAPIComment(comments []string) bool {
+	for _, commentLine := range comments {
+		commentLine = strings.TrimSpace(commentLine)
+		if len(commentLine) == 0 {
+			continue
+		}
+		attribute := strings.ToLower(FieldsByAnySpace(commentLine, 2)[0])
+		switch attribute {
+		// The @summary, @router, @success, @failure annotation belongs to Operation
+		case summaryAttr, routerAttr, successAttr, failureAttr, responseAttr:
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseGeneralAPIInfo parses the general api info (@title, @version, @securitydefinitions.apikey etc.)
+// from the comment lines into swagger, lines without an attribute are ignored.
+func ParseGeneralAPIInfo(swagger *spec.Swagger, comments []string, markDownFileDir string) error {
+	previousAttribute := ""
+	if swagger.SecurityDefinitions == nil {
+		swagger.SecurityDefinitions = spec.SecurityDefinitions{}
+	}
+	if swagger.Info == nil {
+		swagger.Info = &spec.Info{}
+	}
+
+	// parsing classic meta data model
+	for line := 0; line < len(comments); line++ {
+		commentLine := comments[line]
+		commentLine = strings.TrimSpace(commentLine)
+		if len(commentLine) == 0 {
+			continue
+		}
+		fields := FieldsByAnySpace(commentLine, 2)
+
+		attribute := fields[0]
+		var value string
+		if len(fields) > 1 {
+			value = fields[1]
+		}
+
+		switch attr := strings.ToLower(attribute); attr {
+		case versionAttr, titleAttr, tosAttr, licNameAttr, licURLAttr, conNameAttr, conURLAttr, conEmailAttr:
+			setSwaggerInfo(swagger, attr, value)
+		case descriptionAttr:
+			if previousAttribute == attribute {
+				swagger.Info.Description += "\n" + value
+
+				continue
+			}
+
+			setSwaggerInfo(swagger, attr, value)
+		case descriptionMarkdownAttr:
+			commentInfo, err := getMarkdownForTag("api", markDownFileDir)
+			if err != nil {
+				return err
+			}
+
+			setSwaggerInfo(swagger, descriptionAttr, string(commentInfo))
+		case hostAttr:
+			swagger.Host = value
+		case basePathAttr:
+			swagger.BasePath = value
+		case schemesAttr:
+			swagger.Schemes = strings.Split(value, " ")
+		case tagNameAttr:
+			swagger.Tags = append(swagger.Tags, spec.Tag{
+				TagProps: spec.TagProps{
+					Name: value,
+				},
+			})
+		case tagDescriptionAttr, tagDescriptionMarkdownAttr, tagDocsURLAttr, tagDocsDescriptionAttr:
+			if len(swagger.Tags) == 0 {
+				return fmt.Errorf("%s needs to come after a @tag.name", attribute)
+			}
+			tag := &swagger.Tags[len(swagger.Tags)-1]
+			switch attr {
+			case tagDescriptionAttr:
+				tag.TagProps.Description = value
+			case tagDescriptionMarkdownAttr:
+				commentInfo, err := getMarkdownForTag(tag.TagProps.Name, markDownFileDir)
+				if err != nil {
+					return err
+				}
+
+				tag.TagProps.Description = string(commentInfo)
+			case tagDocsURLAttr:
+				tag.TagProps.ExternalDocs = &spec.ExternalDocumentation{
+					URL: value,
+				}
+			case tagDocsDescriptionAttr:
+				if tag.TagProps.ExternalDocs == nil {
+					return fmt.Errorf("%s needs to come after a @tag.docs.url", attribute)
+				}
+
+				tag.TagProps.ExternalDocs.Description = value
+			}
+		case secBasicAttr, secAPIKeyAttr, secApplicationAttr, secImplicitAttr, secPasswordAttr, secAccessCodeAttr:
+			scheme, err := parseSecAttributes(attribute, comments, &line)
+			if err != nil {
+				return err
+			}
+
+			swagger.SecurityDefinitions[value] = scheme
+
+		case securityAttr:
+			swagger.Security = append(swagger.Security, ParseSecurity(value))
+
+		case extDocsDescAttr, extDocsURLAttr:
+			if swagger.ExternalDocs == nil {
+				swagger.ExternalDocs = new(spec.ExternalDocumentation)
+			}
+			switch attr {
+			case extDocsDescAttr:
+				swagger.ExternalDocs.Description = value
+			case extDocsURLAttr:
+				swagger.ExternalDocs.URL = value
+			}
+
+		default:
+			if strings.HasPrefix(attribute, "@x-") {
+				if len(value) == 0 {
+					return fmt.Errorf("annotation %s need a value", attribute)
+				}
+
+				var valueJSON interface{}
+				err := json.Unmarshal([]byte(value), &valueJSON)
+				if err != nil {
+					return fmt.Errorf("annotation %s need a valid json value", attribute)
+				}
+
+				swagger.AddExtension(attribute[1:], valueJSON)
+			}
+		}
+
+		previousAttribute = attribute
+	}
+
+	return nil
+}
+
+func setSwaggerInfo(swagger *spec.Swagger, attribute, value string) {
+	switch attribute {
+	case versionAttr:
+		swagger.Info.Version = value
+	case titleAttr:
+		swagger.Info.Title = value
+	case tosAttr:
+		swagger.Info.TermsOfService = value
+	case descriptionAttr:
+		swagger.Info.Description = value
+	case conNameAttr, conEmailAttr, conURLAttr:
+		if swagger.Info.Contact == nil {
+			swagger.Info.Contact = new(spec.ContactInfo)
+		}
+		switch attribute {
+		case conNameAttr:
+			swagger.Info.Contact.Name = value
+		case conEmailAttr:
+			swagger.Info.Contact.Email = value
+		case conURLAttr:
+			swagger.Info.Contact.URL = value
+		}
+	case licNameAttr, licURLAttr:
+		if swagger.Info.License == nil {
+			swagger.Info.License = new(spec.License)
+		}
+		switch attribute {
+		case licNameAttr:
+			swagger.Info.License.Name = value
+		case licURLAttr:
+			swagger.Info.License.URL = value
+		}
+	}
+}
+
+func parseSecAttributes(context string, lines []string, index *int) (*spec.SecurityScheme, error) {
+	const (
+		in               = "@in"
+		name             = "@name"
+		descriptionAttr  = "@description"
+		tokenURL         = "@tokenurl"
+		authorizationURL = "@authorizationurl"
+	)
+
+	var search []string
+
+	attribute := strings.ToLower(FieldsByAnySpace(strings.TrimSpace(lines[*index]), 2)[0])
+	switch attribute {
+	case secBasicAttr:
+		return spec.BasicAuth(), nil
+	case secAPIKeyAttr:
+		search = []string{in, name}
+	case secApplicationAttr, secPasswordAttr:
+		search = []string{tokenURL}
+	case secImplicitAttr:
+		search = []string{authorizationURL}
+	case secAccessCodeAttr:
+		search = []string{tokenURL, authorizationURL}
+	}
+
+	// For the first line we get the attributes in the context parameter, so we skip to the next one
+	*index++
+
+	attrMap, scopes := make(map[string]string), make(map[string]string)
+	extensions, description := make(map[string]interface{}), ""
+
+loop:
+	for ; *index < len(lines); *index++ {
+		v := strings.TrimSpace(lines[*index])
+		if len(v) == 0 {
+			continue
+		}
+
+		fields := FieldsByAnySpace(v, 2)
+		securityAttr := strings.ToLower(fields[0])
+		var value string
+		if len(fields) > 1 {
+			value = fields[1]
+		}
+
+		for _, findterm := range search {
+			if securityAttr == findterm {
+				attrMap[securityAttr] = value
+
+				break
+			}
+		}
+
+		if strings.HasPrefix(securityAttr, scopeAttrPrefix) {
+			scopes[securityAttr[len(scopeAttrPrefix):]] = strings.TrimSpace(v[len(securityAttr):])
+		}
+
+		if strings.HasPrefix(securityAttr, "@x-") {
+			// Add the custom attribute without the @
+			extensions[securityAttr[1:]] = value
+		}
+
+		// Not mandatory field
+		if securityAttr == descriptionAttr {
+			description = value
+		}
+
+		// next securityDefinitions or any other general attribute ends this one
+		switch {
+		case securityAttr == in, securityAttr == name, securityAttr == tokenURL, securityAttr == authorizationURL,
+			securityAttr == descriptionAttr, strings.HasPrefix(securityAttr, scopeAttrPrefix), strings.HasPrefix(securityAttr, "@x-"):
+			// still an attribute of this security definition
+		case strings.HasPrefix(securityAttr, "@securitydefinitions."), IsAttribute(securityAttr):
+			// Go back to the previous line and break
+			*index--
+
+			break loop
+		}
+	}
+
+	if len(attrMap) != len(search) {
+		return nil, fmt.Errorf("%s is %v required", context, search)
+	}
+
+	var scheme *spec.SecurityScheme
+
+	switch attribute {
+	case secAPIKeyAttr:
+		scheme = spec.APIKeyAuth(attrMap[name], attrMap[in])
+	case secApplicationAttr:
+		scheme = spec.OAuth2Application(attrMap[tokenURL])
+	case secImplicitAttr:
+		scheme = spec.OAuth2Implicit(attrMap[authorizationURL])
+	case secPasswordAttr:
+		scheme = spec.OAuth2Password(attrMap[tokenURL])
+	case secAccessCodeAttr:
+		scheme = spec.OAuth2AccessToken(attrMap[authorizationURL], attrMap[tokenURL])
+	}
+
+	scheme.Description = description
+
+	for extKey, extValue := range extensions {
+		scheme.AddExtension(extKey, extValue)
+	}
+
+	for scope, scopeDescription := range scopes {
+		scheme.AddScope(scope, scopeDescription)
+	}
+
+	return scheme, nil
+}
+
+// ParseSecurity parses a security requirement like "ApiKeyAuth || OAuth2[read, write]" into the scopes of each scheme
+func ParseSecurity(commentLine string) map[string][]string {
+	securityMap := make(map[string][]string)
+
+	for _, securityOption := range strings.Split(commentLine, "||") {
+		securityOption = strings.TrimSpace(securityOption)
+
+		left, right := strings.Index(securityOption, "["), strings.Index(securityOption, "]")
+
+		if left != -1 && right > left {
+			scopes := securityOption[left+1 : right]
+
+			var options []string
+
+			for _, scope := range strings.Split(scopes, ",") {
+				options = append(options, strings.TrimSpace(scope))
+			}
+
+			securityKey := strings.TrimSpace(securityOption[0:left])
+			securityMap[securityKey] = append(securityMap[securityKey], options...)
+		} else {
+			securityKey := strings.TrimSpace(securityOption)
+			securityMap[securityKey] = []string{}
+		}
+	}
+
+	return securityMap
+}
@@ -194,3 +194,21 @@ func FieldsFunc(s string, f func(rune2 rune) bool, n int) []string {
 func FieldsByAnySpace(s string, n int) []string {
 	return FieldsFunc(s, unicode.IsSpace, n)
 }
+
+// IsAttribute reports whether attribute (ex: @Router) is an attribute understood by swag, case insensitively
+func IsAttribute(attribute string) bool {
+	lowerAttribute := strings.ToLower(attribute)
+	switch lowerAttribute {
+	case idAttr, acceptAttr, produceAttr, paramAttr, successAttr, failureAttr, responseAttr, headerAttr,
+		tagsAttr, routerAttr, summaryAttr, deprecatedAttr, securityAttr, titleAttr, conNameAttr, conURLAttr,
+		conEmailAttr, licNameAttr, licURLAttr, versionAttr, descriptionAttr, descriptionMarkdownAttr,
+		secBasicAttr, secAPIKeyAttr, secApplicationAttr, secImplicitAttr, secPasswordAttr, secAccessCodeAttr,
+		tosAttr, extDocsDescAttr, extDocsURLAttr, xCodeSamplesAttr,
+		hostAttr, basePathAttr, schemesAttr, tagNameAttr, tagDescriptionAttr, tagDescriptionMarkdownAttr,
+		tagDocsURLAttr, tagDocsDescriptionAttr, "@query.collection.format",
+		// attributes of a @securitydefinitions.*
+		"@in", "@name", "@tokenurl", "@authorizationurl":
+		return true
+	}
+	return strings.HasPrefix(lowerAttribute, scopeAttrPrefix) || strings.HasPrefix(lowerAttribute, "@x-")
+}
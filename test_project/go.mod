@@ -1,3 +1,11 @@
 module github.com/ondbyte/test_project
 
 go 1.20
+
+require (
+	github.com/julienschmidt/httprouter v1.3.0
+	github.com/ondbyte/matte v0.0.0
+)
+
+// the matte of this repo
+replace github.com/ondbyte/matte => ../
@@ -1,6 +1,17 @@
 package handlers
 
-// @path("GET","/hello")
+// @path("GET","/hello/:yadu/:chinamya")
+// @query("yadu2")
 func Hello(yadu string, chinamya uint, yadu2 *int) (jsonResponse string) {
 	return "Hello"
 }
+
+type Greeting struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+// @path("POST","/greetings")
+// @body("greeting")
+func CreateGreeting(greeting Greeting) {
+}
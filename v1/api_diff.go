@@ -0,0 +1,382 @@
+package matte
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ChangeKind tells whether a change of the api breaks its existing clients
+type ChangeKind string
+
+const (
+	// the change breaks existing clients, ex: a removed route or a newly required param
+	Breaking ChangeKind = "breaking"
+	// existing clients keep working, ex: a new route or a new optional param
+	Additive ChangeKind = "additive"
+)
+
+// APIChange is a change of a route between two versions of the api
+type APIChange struct {
+	Kind   ChangeKind `json:"kind"`
+	Method string     `json:"method"`
+	Path   string     `json:"path"`
+	Msg    string     `json:"msg"`
+}
+
+func (c APIChange) String() string {
+	return fmt.Sprintf("%v: %v %v: %v", c.Kind, c.Method, c.Path, c.Msg)
+}
+
+// APIChanges are the changes of the api, breaking ones first
+type APIChanges []APIChange
+
+// Breaking reports whether any of the changes breaks the existing clients
+func (cs APIChanges) Breaking() bool {
+	for _, c := range cs {
+		if c.Kind == Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// APIDiff compares the routes of the project against the openapi document at basePath, usually the
+// openapi.json of a previous build committed as a snapshot
+func APIDiff(fileSet *token.FileSet, project, basePath string) (APIChanges, error) {
+	base, err := openapi3.NewLoader().LoadFromFile(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the base openapi document %v due to err: %v", basePath, err)
+	}
+	m, err := Load(fileSet, project)
+	if err != nil {
+		return nil, err
+	}
+	return DiffOpenAPI(base, m.OpenAPI()), nil
+}
+
+// WriteAPISnapshot writes the openapi document of the project to path, to be committed and compared against by APIDiff
+func WriteAPISnapshot(fileSet *token.FileSet, project, path string) error {
+	m, err := Load(fileSet, project)
+	if err != nil {
+		return err
+	}
+	jsonBytes, err := json.MarshalIndent(m.OpenAPI(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi document as json due to err: %v", err)
+	}
+	err = os.WriteFile(path, jsonBytes, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", path, err)
+	}
+	return nil
+}
+
+// DiffOpenAPI returns the changes of the operations of current compared to base
+func DiffOpenAPI(base, current *openapi3.T) APIChanges {
+	d := &apiDiffer{}
+	baseOps, currentOps := operationsByRoute(base), operationsByRoute(current)
+	for _, route := range sortedKeys(baseOps) {
+		baseOp := baseOps[route]
+		d.method, d.path = baseOp.method, baseOp.path
+		currentOp := currentOps[route]
+		if currentOp == nil {
+			d.add(Breaking, "route is removed")
+			continue
+		}
+		d.path = currentOp.path
+		d.operation(baseOp, currentOp)
+	}
+	for _, route := range sortedKeys(currentOps) {
+		if baseOps[route] == nil {
+			d.method, d.path = currentOps[route].method, currentOps[route].path
+			d.add(Additive, "route is added")
+		}
+	}
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Kind == Breaking && d.changes[j].Kind != Breaking
+	})
+	return d.changes
+}
+
+type routeOperation struct {
+	method, path string
+	pathItem     *openapi3.PathItem
+	op           *openapi3.Operation
+}
+
+// returns the operations of the doc by their method and path, names of the path params are left out of the key
+// as renaming one changes nothing for the clients
+func operationsByRoute(doc *openapi3.T) map[string]*routeOperation {
+	ops := map[string]*routeOperation{}
+	for path, pathItem := range doc.Paths.Map() {
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, "{") {
+				segments[i] = "{}"
+			}
+		}
+		for method, op := range pathItem.Operations() {
+			ops[method+" "+strings.Join(segments, "/")] = &routeOperation{method, path, pathItem, op}
+		}
+	}
+	return ops
+}
+
+type apiDiffer struct {
+	method, path string
+	changes      APIChanges
+}
+
+func (d *apiDiffer) add(kind ChangeKind, format string, args ...any) {
+	d.changes = append(d.changes, APIChange{Kind: kind, Method: d.method, Path: d.path, Msg: fmt.Sprintf(format, args...)})
+}
+
+// path params are matched by their position, query params by their name
+func paramKey(params []*openapi3.Parameter, p *openapi3.Parameter) string {
+	if p.In != openapi3.ParameterInPath {
+		return p.In + " " + p.Name
+	}
+	position := 0
+	for _, other := range params {
+		if other == p {
+			break
+		}
+		if other.In == openapi3.ParameterInPath {
+			position++
+		}
+	}
+	return p.In + " " + strconv.Itoa(position)
+}
+
+func operationParams(ro *routeOperation) (keys []string, params map[string]*openapi3.Parameter) {
+	all := []*openapi3.Parameter{}
+	for _, p := range append(append(openapi3.Parameters{}, ro.pathItem.Parameters...), ro.op.Parameters...) {
+		if p.Value != nil {
+			all = append(all, p.Value)
+		}
+	}
+	params = map[string]*openapi3.Parameter{}
+	for _, p := range all {
+		key := paramKey(all, p)
+		if params[key] == nil {
+			keys = append(keys, key)
+		}
+		params[key] = p
+	}
+	return keys, params
+}
+
+func (d *apiDiffer) operation(base, current *routeOperation) {
+	baseKeys, baseParams := operationParams(base)
+	currentKeys, currentParams := operationParams(current)
+	for _, key := range baseKeys {
+		baseParam, currentParam := baseParams[key], currentParams[key]
+		if currentParam == nil {
+			// the clients sending a required param expect it to be read
+			if baseParam.Required {
+				d.add(Breaking, "required %v param '%v' is removed", baseParam.In, baseParam.Name)
+			} else {
+				d.add(Additive, "optional %v param '%v' is removed", baseParam.In, baseParam.Name)
+			}
+			continue
+		}
+		where := fmt.Sprintf("%v param '%v'", currentParam.In, currentParam.Name)
+		if currentParam.Required && !baseParam.Required {
+			d.add(Breaking, "%v is now required", where)
+		} else if !currentParam.Required && baseParam.Required {
+			d.add(Additive, "%v is now optional", where)
+		}
+		d.schema(where, baseParam.Schema, currentParam.Schema, true, true, map[[2]*openapi3.Schema]bool{})
+	}
+	for _, key := range currentKeys {
+		if baseParams[key] != nil {
+			continue
+		}
+		p := currentParams[key]
+		if p.Required {
+			d.add(Breaking, "required %v param '%v' is added", p.In, p.Name)
+		} else {
+			d.add(Additive, "optional %v param '%v' is added", p.In, p.Name)
+		}
+	}
+	d.requestBody(base.op.RequestBody, current.op.RequestBody)
+	d.response(base.op, current.op)
+}
+
+func (d *apiDiffer) requestBody(base, current *openapi3.RequestBodyRef) {
+	switch {
+	case base == nil && current == nil:
+		return
+	case current == nil && base.Value.Required:
+		d.add(Breaking, "required request body is removed")
+		return
+	case current == nil:
+		d.add(Additive, "optional request body is removed")
+		return
+	case base == nil && current.Value.Required:
+		d.add(Breaking, "required request body is added")
+		return
+	case base == nil:
+		d.add(Additive, "optional request body is added")
+		return
+	}
+	if current.Value.Required && !base.Value.Required {
+		d.add(Breaking, "request body is now required")
+	}
+	baseMedia, currentMedia := base.Value.Content.Get(ContentTypeJSON), current.Value.Content.Get(ContentTypeJSON)
+	if baseMedia != nil && currentMedia != nil {
+		d.schema("request body", baseMedia.Schema, currentMedia.Schema, true, false, map[[2]*openapi3.Schema]bool{})
+	}
+}
+
+// returns the status and the response of the success of the operation
+func successResponse(op *openapi3.Operation) (int, *openapi3.Response) {
+	codes := []int{}
+	for code := range op.Responses.Map() {
+		if c, err := strconv.Atoi(code); err == nil && c >= 200 && c < 300 {
+			codes = append(codes, c)
+		}
+	}
+	if len(codes) == 0 {
+		return 0, nil
+	}
+	sort.Ints(codes)
+	return codes[0], op.Responses.Status(codes[0]).Value
+}
+
+func (d *apiDiffer) response(base, current *openapi3.Operation) {
+	baseStatus, baseResponse := successResponse(base)
+	currentStatus, currentResponse := successResponse(current)
+	switch {
+	case baseResponse == nil && currentResponse == nil:
+		return
+	case currentResponse == nil:
+		d.add(Breaking, "success response %v is removed", baseStatus)
+		return
+	case baseResponse == nil:
+		d.add(Additive, "success response %v is added", currentStatus)
+		return
+	}
+	if baseStatus != currentStatus {
+		d.add(Breaking, "success status changed from %v to %v", baseStatus, currentStatus)
+	}
+	for _, contentType := range sortedKeys(baseResponse.Content) {
+		currentMedia := currentResponse.Content.Get(contentType)
+		if currentMedia == nil {
+			d.add(Breaking, "response is no more %v", contentType)
+			continue
+		}
+		d.schema("response", baseResponse.Content.Get(contentType).Schema, currentMedia.Schema, false, false, map[[2]*openapi3.Schema]bool{})
+	}
+}
+
+// compares the schemas of a request (sent by the clients) or a response (read by the clients),
+// wire is true if the value is sent as a string like the path and query params
+func (d *apiDiffer) schema(where string, baseRef, currentRef *openapi3.SchemaRef, request, wire bool, seen map[[2]*openapi3.Schema]bool) {
+	if baseRef == nil || currentRef == nil || baseRef.Value == nil || currentRef.Value == nil {
+		return
+	}
+	base, current := baseRef.Value, currentRef.Value
+	if seen[[2]*openapi3.Schema{base, current}] {
+		return
+	}
+	seen[[2]*openapi3.Schema{base, current}] = true
+
+	if typeName(base) != typeName(current) {
+		switch {
+		case request && (current.Type == "" || widens(base, current, wire)):
+			d.add(Additive, "type of %v is widened from %v to %v", where, typeName(base), typeName(current))
+		case !request && base.Type == "":
+			d.add(Additive, "type of %v is now %v", where, typeName(current))
+		case request:
+			d.add(Breaking, "type of %v is narrowed from %v to %v", where, typeName(base), typeName(current))
+		default:
+			d.add(Breaking, "type of %v changed from %v to %v", where, typeName(base), typeName(current))
+		}
+		return
+	}
+	if request {
+		if len(base.Enum) == 0 && len(current.Enum) > 0 {
+			d.add(Breaking, "%v is now limited to %v", where, current.Enum)
+		}
+		for _, value := range base.Enum {
+			if !containsValue(current.Enum, value) && len(current.Enum) > 0 {
+				d.add(Breaking, "value %v of %v is no more accepted", value, where)
+			}
+		}
+	}
+	if base.Items != nil && current.Items != nil {
+		d.schema(where+" items", base.Items, current.Items, request, wire, seen)
+	}
+	for _, name := range sortedKeys(base.Properties) {
+		property := fmt.Sprintf("property '%v' of %v", name, where)
+		currentProperty := current.Properties[name]
+		baseRequired, currentRequired := contains(base.Required, name), contains(current.Required, name)
+		switch {
+		case currentProperty == nil && request:
+			d.add(Additive, "%v is removed", property)
+		case currentProperty == nil:
+			d.add(Breaking, "%v is removed", property)
+		case request && currentRequired && !baseRequired:
+			d.add(Breaking, "%v is now required", property)
+		case !request && baseRequired && !currentRequired:
+			d.add(Breaking, "%v is now optional", property)
+		}
+		if currentProperty != nil {
+			d.schema(property, base.Properties[name], currentProperty, request, false, seen)
+		}
+	}
+	for _, name := range sortedKeys(current.Properties) {
+		if base.Properties[name] != nil {
+			continue
+		}
+		property := fmt.Sprintf("property '%v' of %v", name, where)
+		if request && contains(current.Required, name) {
+			d.add(Breaking, "required %v is added", property)
+		} else {
+			d.add(Additive, "%v is added", property)
+		}
+	}
+}
+
+// returns the type of the schema along with its format, ex: integer(int32)
+func typeName(schema *openapi3.Schema) string {
+	if schema.Type == "" {
+		return "any"
+	}
+	if schema.Format != "" && schema.Type != openapi3.TypeString {
+		return schema.Type + "(" + schema.Format + ")"
+	}
+	return schema.Type
+}
+
+// reports whether every value of base is a valid value of current, wire values are strings so anything widens to a string
+func widens(base, current *openapi3.Schema, wire bool) bool {
+	if wire && current.Type == openapi3.TypeString {
+		return true
+	}
+	bits := map[string]int{"int32": 32, "int64": 64, "": 64, "float": 32, "double": 64}
+	switch {
+	case base.Type == current.Type && (base.Type == openapi3.TypeInteger || base.Type == openapi3.TypeNumber):
+		return bits[base.Format] <= bits[current.Format]
+	case base.Type == openapi3.TypeInteger && current.Type == openapi3.TypeNumber:
+		return true
+	}
+	return false
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
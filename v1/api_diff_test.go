@@ -0,0 +1,149 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestAPIDiff(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+type User struct {
+	Name string ` + "`json:\"name\"`" + `
+	Age  int    ` + "`json:\"age\"`" + `
+}
+
+// @path("GET","/users/:id")
+// @query("verbose")
+func Get(id int32, verbose bool) *User { return nil }
+
+// @path("DELETE","/users/:id")
+func Delete(id string) {}
+`,
+	})
+	snapshot := filepath.Join(t.TempDir(), "openapi.json")
+	if !assert.NoError(matte.WriteAPISnapshot(token.NewFileSet(), dir, snapshot)) {
+		return
+	}
+	changes, err := matte.APIDiff(token.NewFileSet(), dir, snapshot)
+	if assert.NoError(err) {
+		assert.Empty(changes)
+	}
+
+	os.WriteFile(filepath.Join(dir, "users", "users.go"), []byte(`
+package users
+
+type User struct {
+	Name  string `+"`json:\"name\"`"+`
+	Email string `+"`json:\"email\"`"+`
+}
+
+// @path("GET","/users/:userID")
+// @query("verbose","limit")
+func Get(userID int64, verbose bool, limit int) *User { return nil }
+
+// @path("POST","/users")
+// @body("u",4096)
+func Create(u User) {}
+`), 0666)
+	changes, err = matte.APIDiff(token.NewFileSet(), dir, snapshot)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.True(changes.Breaking())
+	msgs := []string{}
+	for _, c := range changes {
+		msgs = append(msgs, c.String())
+	}
+	assert.Equal([]string{
+		"breaking: DELETE /users/{id}: route is removed",
+		"breaking: GET /users/{userID}: required query param 'limit' is added",
+		"breaking: GET /users/{userID}: property 'age' of response is removed",
+		"additive: GET /users/{userID}: type of path param 'userID' is widened from integer(int32) to integer(int64)",
+		"additive: GET /users/{userID}: property 'email' of response is added",
+		"additive: POST /users: route is added",
+	}, msgs)
+}
+
+func TestDiffOpenAPIRemovals(t *testing.T) {
+	// the document of a single POST /users operation
+	doc := func(t *testing.T, operation string) *openapi3.T {
+		doc, err := openapi3.NewLoader().LoadFromData([]byte(`
+openapi: 3.0.3
+info: {title: users, version: 1.0.0}
+paths:
+  /users:
+    post:
+` + operation))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	created := `
+      responses:
+        "201": {description: created, content: {application/json: {schema: {type: string}}}}
+`
+	failed := `
+      responses:
+        "400": {description: failed}
+`
+	tests := []struct {
+		name, base, current string
+		changes             []string
+	}{{
+		name: "required query param",
+		base: `
+      parameters:
+        - {name: q, in: query, required: true, schema: {type: string}}` + created,
+		current: created,
+		changes: []string{"breaking: POST /users: required query param 'q' is removed"},
+	}, {
+		name: "optional query param",
+		base: `
+      parameters:
+        - {name: q, in: query, schema: {type: string}}` + created,
+		current: created,
+		changes: []string{"additive: POST /users: optional query param 'q' is removed"},
+	}, {
+		name: "required request body",
+		base: `
+      requestBody: {required: true, content: {application/json: {schema: {type: string}}}}` + created,
+		current: created,
+		changes: []string{"breaking: POST /users: required request body is removed"},
+	}, {
+		name: "optional request body",
+		base: `
+      requestBody: {content: {application/json: {schema: {type: string}}}}` + created,
+		current: created,
+		changes: []string{"additive: POST /users: optional request body is removed"},
+	}, {
+		name:    "success response removed",
+		base:    created,
+		current: failed,
+		changes: []string{"breaking: POST /users: success response 201 is removed"},
+	}, {
+		name:    "success response added",
+		base:    failed,
+		current: created,
+		changes: []string{"additive: POST /users: success response 201 is added"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			msgs := []string{}
+			for _, c := range matte.DiffOpenAPI(doc(t, test.base), doc(t, test.current)) {
+				msgs = append(msgs, c.String())
+			}
+			a.Equal(t, test.changes, msgs)
+		})
+	}
+}
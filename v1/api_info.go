@@ -0,0 +1,206 @@
+package matte
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+	"github.com/ondbyte/matte/swaggo"
+)
+
+const apiInfoHint = `ex: @title users api, @version 1.0.0 or @securitydefinitions.apikey ApiKeyAuth followed by @in header and @name Authorization`
+
+// ParseGeneralAPIInfo reads the general api info of the project, like @title, @version, @description, @contact.*,
+// @license.* and @securitydefinitions.* from the doc of the MatteApp func, or the pkg doc of the core pkg
+// when there is no MatteApp func. it is the same syntax swag reads from your main.go
+func (m *Matte) ParseGeneralAPIInfo() error {
+	m.apiInfo = &spec.Swagger{}
+	m.apiInfo.Info = &spec.Info{}
+	m.apiInfo.SecurityDefinitions = spec.SecurityDefinitions{}
+	doc, file := m.generalAPIDoc()
+	if doc == nil {
+		return nil
+	}
+	comments := strings.Split(doc.Text(), "\n")
+	if !swaggo.IsGeneralAPIComment(comments) {
+		return errorAt(doc.Pos(), doc.List[0].Text, "move the annotations of the handler to the doc of the handler",
+			"doc of %v describes an operation, but it should describe the api", GeneralApiInfoFuncName)
+	}
+	markDownFileDir := filepath.Dir(m.fileSet.Position(file.Pos()).Filename)
+	err := swaggo.ParseGeneralAPIInfo(m.apiInfo, comments, markDownFileDir)
+	if err != nil {
+		return errorAt(doc.Pos(), doc.List[0].Text, apiInfoHint, "invalid general api info: %v", err)
+	}
+	return nil
+}
+
+// returns the doc having the general api info, the doc of the MatteApp func takes precedence over the pkg doc
+func (m *Matte) generalAPIDoc() (*ast.CommentGroup, *ast.File) {
+	if m.corePkg == nil {
+		return nil, nil
+	}
+	fileNames := make([]string, 0, len(m.corePkg.Files))
+	for fileName := range m.corePkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		file := m.corePkg.Files[fileName]
+		for _, decl := range file.Decls {
+			fnDecl, ok := decl.(*ast.FuncDecl)
+			if ok && fnDecl.Recv == nil && fnDecl.Name.Name == GeneralApiInfoFuncName && fnDecl.Doc != nil {
+				return fnDecl.Doc, file
+			}
+		}
+	}
+	for _, fileName := range fileNames {
+		file := m.corePkg.Files[fileName]
+		if file.Doc != nil {
+			return file.Doc, file
+		}
+	}
+	return nil, nil
+}
+
+// title of the api, the module path unless set by @title
+func (m *Matte) apiTitle() string {
+	if m.apiInfo != nil && m.apiInfo.Info.Title != "" {
+		return m.apiInfo.Info.Title
+	}
+	return m.modFile.Module.Mod.Path
+}
+
+// version of the api, 0.0.0 unless set by @version
+func (m *Matte) apiVersion() string {
+	if m.apiInfo != nil && m.apiInfo.Info.Version != "" {
+		return m.apiInfo.Info.Version
+	}
+	return "0.0.0"
+}
+
+// sets the info, tags, security schemes and requirements of the doc from the general api info
+func (m *Matte) applyGeneralAPIInfo(doc *openapi3.T) {
+	doc.Info.Title = m.apiTitle()
+	doc.Info.Version = m.apiVersion()
+	if m.apiInfo == nil {
+		return
+	}
+	info := m.apiInfo.Info
+	doc.Info.Description = info.Description
+	doc.Info.TermsOfService = info.TermsOfService
+	if info.Contact != nil {
+		doc.Info.Contact = &openapi3.Contact{Name: info.Contact.Name, URL: info.Contact.URL, Email: info.Contact.Email}
+	}
+	if info.License != nil {
+		doc.Info.License = &openapi3.License{Name: info.License.Name, URL: info.License.URL}
+	}
+	for _, tag := range m.apiInfo.Tags {
+		doc.Tags = append(doc.Tags, &openapi3.Tag{
+			Name:         tag.Name,
+			Description:  tag.Description,
+			ExternalDocs: externalDocs(tag.ExternalDocs),
+		})
+	}
+	doc.ExternalDocs = externalDocs(m.apiInfo.ExternalDocs)
+	if len(m.apiInfo.SecurityDefinitions) > 0 {
+		doc.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+		for name, scheme := range m.apiInfo.SecurityDefinitions {
+			doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: securityScheme(scheme)}
+		}
+	}
+	if len(m.apiInfo.Security) > 0 {
+		doc.Security = securityRequirements(m.apiInfo.Security)
+	}
+	for name, value := range m.apiInfo.Extensions {
+		if doc.Extensions == nil {
+			doc.Extensions = map[string]interface{}{}
+		}
+		doc.Extensions[name] = value
+	}
+}
+
+func externalDocs(docs *spec.ExternalDocumentation) *openapi3.ExternalDocs {
+	if docs == nil {
+		return nil
+	}
+	return &openapi3.ExternalDocs{Description: docs.Description, URL: docs.URL}
+}
+
+// converts a swagger 2 security definition to an openapi 3 security scheme
+func securityScheme(scheme *spec.SecurityScheme) *openapi3.SecurityScheme {
+	s := openapi3.NewSecurityScheme()
+	s.Description = scheme.Description
+	switch scheme.Type {
+	case "basic":
+		s.Type, s.Scheme = "http", "basic"
+	case "apiKey":
+		s.Type, s.In, s.Name = "apiKey", scheme.In, scheme.Name
+	case "oauth2":
+		s.Type = "oauth2"
+		flow := &openapi3.OAuthFlow{
+			AuthorizationURL: scheme.AuthorizationURL,
+			TokenURL:         scheme.TokenURL,
+			Scopes:           map[string]string{},
+		}
+		for scope, description := range scheme.Scopes {
+			flow.Scopes[scope] = description
+		}
+		s.Flows = &openapi3.OAuthFlows{}
+		switch scheme.Flow {
+		case "application":
+			s.Flows.ClientCredentials = flow
+		case "implicit":
+			s.Flows.Implicit = flow
+		case "password":
+			s.Flows.Password = flow
+		case "accessCode":
+			s.Flows.AuthorizationCode = flow
+		}
+	}
+	return s
+}
+
+func securityRequirements(security []map[string][]string) openapi3.SecurityRequirements {
+	requirements := openapi3.SecurityRequirements{}
+	for _, schemes := range security {
+		requirement := openapi3.NewSecurityRequirement()
+		for name, scopes := range schemes {
+			requirement.Authenticate(name, scopes...)
+		}
+		requirements.With(requirement)
+	}
+	return requirements
+}
+
+// adds a diagnostic for each security scheme required by a route but not defined by a @securitydefinitions.* annotation
+func (m *Matte) checkSecurity() {
+	defined := spec.SecurityDefinitions{}
+	if m.apiInfo != nil {
+		defined = m.apiInfo.SecurityDefinitions
+	}
+	for _, route := range m.Routes {
+		for _, schemes := range route.Security {
+			for name := range schemes {
+				if defined[name] == nil {
+					m.addError(errorAt(route.Pos, "", fmt.Sprintf("define it in the doc of %v, %v", GeneralApiInfoFuncName, apiInfoHint),
+						"handler %v requires security scheme '%v' which is not defined", route.Handler, name))
+				}
+			}
+		}
+	}
+}
+
+// returns the src mounting the version of the app, if the config enables it
+func (m *Matte) versionSrc() string {
+	m.requireImport(WebImportPath)
+	src := fmt.Sprintf(`version := web.NewVersion(%q, %q)`, m.apiTitle(), m.apiVersion())
+	if m.config.VersionPath != "" {
+		src += fmt.Sprintf(`
+		router.Handler("GET", %q, web.VersionHandler(version))`, m.config.VersionPath)
+	}
+	return src
+}
@@ -0,0 +1,83 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildReadsGeneralAPIInfo(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"config.matte.go": `package main
+
+// MatteApp configures the app
+//
+// @title        users api
+// @version      1.2.0
+// @description  manages the users
+// @description  of the company
+// @contact.name platform team
+// @license.name MIT
+// @securitydefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+func MatteApp() {}
+`,
+		"users/users.go": `
+package users
+
+// @path("GET","/users/:id")
+// @security("ApiKeyAuth")
+func Get(id string) string { return "" }
+
+// @Router /admins [get]
+// @Security Basic
+func Admins() string { return "" }
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "handler users.Admins requires security scheme 'Basic' which is not defined")
+		assert.NotContains(err.Error(), "ApiKeyAuth' which is not defined")
+	}
+
+	os.WriteFile(filepath.Join(dir, "users", "users.go"), []byte(`
+package users
+
+// @path("GET","/users/:id")
+// @security("ApiKeyAuth")
+func Get(id string) string { return "" }
+`), 0666)
+	err = matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join(dir, matte.MatteDir, matte.OpenAPIJSONFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("users api", doc.Info.Title)
+	assert.Equal("1.2.0", doc.Info.Version)
+	assert.Equal("manages the users\nof the company", doc.Info.Description)
+	assert.Equal("platform team", doc.Info.Contact.Name)
+	assert.Equal("MIT", doc.Info.License.Name)
+	if assert.Contains(doc.Components.SecuritySchemes, "ApiKeyAuth") {
+		scheme := doc.Components.SecuritySchemes["ApiKeyAuth"].Value
+		assert.Equal("apiKey", scheme.Type)
+		assert.Equal("header", scheme.In)
+		assert.Equal("Authorization", scheme.Name)
+	}
+	op := doc.Paths.Find("/users/{id}").Get
+	if assert.NotNil(op.Security) {
+		assert.Equal(openapi3.SecurityRequirements{{"ApiKeyAuth": []string{}}}, *op.Security)
+	}
+	app := readApp(t, dir)
+	assert.Contains(app, `version := web.NewVersion("users api", "1.2.0")`)
+	assert.Contains(app, `router.Handler("GET", "/version", web.VersionHandler(version))`)
+}
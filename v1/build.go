@@ -1,25 +1,202 @@
 package matte
 
 import (
+	"bytes"
 	"fmt"
+	"go/token"
 	"io"
 	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/ondbyte/matte/web"
 	"golang.org/x/mod/modfile"
 )
 
-func BuildProject(dir string, stdOut, stdErr io.Writer) error {
-	/* cmd := exec.Command("go", "build", ".")
-	cmd.Stdout = stdOut
-	cmd.Stderr = stdErr
-	err = cmd.Run()
+// BuildOptions are passed through to go build
+type BuildOptions struct {
+	// path of the binary, by default the name of the module in the project dir
+	Output  string
+	LDFlags string
+	// comma separated build tags
+	Tags string
+	Race bool
+	// target of the binary, the environment decides it when empty
+	GOOS   string
+	GOARCH string
+	// output of go build is streamed into these, compiler errors in the generated src are mapped back to their handler
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// BuildProject generates the app of the project and compiles it using the go toolchain
+func BuildProject(fileSet *token.FileSet, project string, options BuildOptions) error {
+	m, err := generate(fileSet, project, web.Env())
 	if err != nil {
 		return err
-	} */
+	}
+	return m.compile(options)
+}
+
+// returns the path of the binary built for the options
+func (m *Matte) binaryPath(options BuildOptions) string {
+	if options.Output != "" {
+		return options.Output
+	}
+	goos := options.GOOS
+	if goos == "" {
+		goos = os.Getenv("GOOS")
+	}
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	name := path.Base(m.modFile.Module.Mod.Path)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(m.wd, name)
+}
+
+// runs go build on the generated app
+func (m *Matte) compile(options BuildOptions) error {
+	output, err := filepath.Abs(m.binaryPath(options))
+	if err != nil {
+		return fmt.Errorf("unable to resolve the output path due to err: %v", err)
+	}
+	args := []string{"build", "-o", output}
+	if options.LDFlags != "" {
+		args = append(args, "-ldflags", options.LDFlags)
+	}
+	if options.Tags != "" {
+		args = append(args, "-tags", options.Tags)
+	}
+	if options.Race {
+		args = append(args, "-race")
+	}
+	args = append(args, "./"+filepath.ToSlash(filepath.Clean(m.config.OutputDir)))
+	cmd := exec.Command("go", args...)
+	cmd.Dir = m.wd
+	cmd.Env = os.Environ()
+	if options.GOOS != "" {
+		cmd.Env = append(cmd.Env, "GOOS="+options.GOOS)
+	}
+	if options.GOARCH != "" {
+		cmd.Env = append(cmd.Env, "GOARCH="+options.GOARCH)
+	}
+	if options.Race {
+		// the race detector requires cgo
+		cmd.Env = append(cmd.Env, "CGO_ENABLED=1")
+	}
+	appSrc, err := os.ReadFile(filepath.Join(m.matteDir, AppFile))
+	if err != nil {
+		return fmt.Errorf("failed to read file %v due to err: %v", AppFile, err)
+	}
+	cmd.Stdout = options.Stdout
+	stderr := &compilerOutput{m: m, w: options.Stderr, routeLines: m.routeLines(string(appSrc))}
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	stderr.flush()
+	if err != nil {
+		return fmt.Errorf("unable to compile the app due to err: %v", err)
+	}
 	return nil
 }
 
+var routeHandleLine = regexp.MustCompile(`router\.Handle\("([A-Z]+)",\s*("(?:[^"\\]|\\.)*")`)
+
+// returns the route registered at each line of the src of the app, a line of the src belongs to the route
+// registered on the closest line above it, mounts of the builtin paths end the last route
+func (m *Matte) routeLines(appSrc string) map[int]*Route {
+	lines := map[int]*Route{}
+	for i, line := range strings.Split(appSrc, "\n") {
+		match := routeHandleLine.FindStringSubmatch(line)
+		switch {
+		case match != nil:
+			routePath, _ := strconv.Unquote(match[2])
+			for _, route := range m.Routes {
+				if route.Method == match[1] && route.Path == routePath {
+					lines[i+1] = route
+				}
+			}
+		case strings.Contains(line, "router.Handler(") || strings.Contains(line, "web.NewVersion("):
+			lines[i+1] = nil
+		}
+	}
+	return lines
+}
+
+// file:line:col: msg, as printed by the go compiler
+var compilerError = regexp.MustCompile(`^(\S+\.go):(\d+):(\d+): (.*)$`)
+
+// writes the output of go build into w, line by line, an error in the generated src of a handler is
+// reported at the handler, the rest as is
+type compilerOutput struct {
+	m          *Matte
+	w          io.Writer
+	routeLines map[int]*Route
+	buf        bytes.Buffer
+}
+
+func (c *compilerOutput) Write(p []byte) (int, error) {
+	c.buf.Write(p)
+	for {
+		line, err := c.buf.ReadString('\n')
+		if err != nil {
+			// an incomplete line waits for the rest of it
+			c.buf.WriteString(line)
+			return len(p), nil
+		}
+		c.writeLine(strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func (c *compilerOutput) flush() {
+	if c.buf.Len() > 0 {
+		c.writeLine(c.buf.String())
+		c.buf.Reset()
+	}
+}
+
+func (c *compilerOutput) writeLine(line string) {
+	if c.w == nil {
+		return
+	}
+	match := compilerError.FindStringSubmatch(line)
+	outputDir := filepath.ToSlash(filepath.Clean(c.m.config.OutputDir))
+	if match != nil && filepath.Base(match[1]) == AppFile && strings.HasSuffix(filepath.ToSlash(filepath.Dir(match[1])), outputDir) {
+		lineNumber, _ := strconv.Atoi(match[2])
+		if route := c.routeAt(lineNumber); route != nil {
+			line = fmt.Sprintf("%v: generated src of handler %v does not compile: %v\n\tat %v:%v:%v",
+				c.m.fileSet.Position(route.Pos), route.Handler, match[4], match[1], match[2], match[3])
+		}
+	}
+	fmt.Fprintln(c.w, line)
+}
+
+// returns the route whose src has the line of the app, nil if the line is not of a route
+func (c *compilerOutput) routeAt(line int) *Route {
+	starts := make([]int, 0, len(c.routeLines))
+	for start := range c.routeLines {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+	var route *Route
+	for _, start := range starts {
+		if start > line {
+			break
+		}
+		route = c.routeLines[start]
+	}
+	return route
+}
+
 // returns the package path of the a project at a dir
 // this only returns if the dir is a golang project ie: has a go.mod file
 func GetPackagePathFromGoMod(dir string) (string, error) {
@@ -0,0 +1,42 @@
+package matte_test
+
+import (
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildProjectCompilesTheApp(t *testing.T) {
+	assert := a.New(t)
+	dir := newCompilableTestProject(t, map[string]string{
+		"users/users.go": usersPkg + `
+// @path("GET","/health")
+func Health() {}
+`,
+	})
+	output := filepath.Join(t.TempDir(), "users")
+	stderr := &strings.Builder{}
+	err := matte.BuildProject(token.NewFileSet(), dir, matte.BuildOptions{Output: output, Stderr: stderr})
+	if !assert.NoError(err, stderr.String()) {
+		return
+	}
+	assert.FileExists(output)
+
+	writeFiles(t, dir, map[string]string{"users/users.go": usersPkg + `
+type user struct{}
+
+// @path("PUT","/users/:id")
+// @body("u")
+func Update(id string, u user) {}
+`})
+	stderr.Reset()
+	err = matte.BuildProject(token.NewFileSet(), dir, matte.BuildOptions{Output: output, Stderr: stderr})
+	if assert.Error(err) {
+		assert.Contains(stderr.String(), filepath.Join(dir, "users", "users.go")+":9:4: generated src of handler users.Update does not compile: name user not exported by package users")
+		assert.NotContains(stderr.String(), "handler users.Get")
+	}
+}
@@ -0,0 +1,309 @@
+package matte
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dir of the generated go client inside the matte dir, and its pkg name
+const ClientDir = "client"
+
+// file of the generated go client
+const ClientFile = "client.go"
+
+// names used by the src of a client method, params having one of them are renamed
+var clientLocals = []string{"c", "ctx", "query", "resp", "err", "result", "text", "body", "url", "web", "context"}
+
+// pkgs the src of the client refers to by their names
+var clientImports = map[string]string{
+	"context": "context",
+	"json":    "encoding/json",
+	"url":     "net/url",
+	"web":     WebImportPath,
+}
+
+type clientGen struct {
+	m       *Matte
+	imports *importSet
+}
+
+func (g *clientGen) requireImport(importPath string) {
+	g.imports.add(importPath, assumedPkgName(importPath))
+}
+
+// ClientSrc returns the src of a go client having a method for every route of the project, types of the params
+// and results are the ones of the handlers, so the client imports the pkgs declaring them
+func (m *Matte) ClientSrc() ([]byte, error) {
+	g := &clientGen{m: m, imports: newImportSet(clientImports, append([]string{"Client", "New"}, clientLocals...)...)}
+	g.requireImport("context")
+	g.requireImport(WebImportPath)
+	names := m.clientNames()
+	methods := ""
+	for _, route := range m.Routes {
+		methods += g.method(names[route], route)
+	}
+	src := fmt.Sprintf(`// Code generated by matte. DO NOT EDIT.
+
+// Package client calls the routes of %v, every handler is a method of Client
+package client
+
+import (
+	%v
+)
+
+// Client of %v, set its Header to send headers like Authorization with every request
+type Client struct {
+	web.Client
+}
+
+// New returns a client of the app served at baseURL, ex: http://users:8000
+func New(baseURL string) *Client {
+	return &Client{web.Client{BaseURL: baseURL}}
+}
+%v`, m.apiTitle(), g.importsSrc(), m.apiTitle(), methods)
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format go client src due to err: %v", err)
+	}
+	return formatted, nil
+}
+
+// returns the imports of the client, the std lib ones first
+func (g *clientGen) importsSrc() string {
+	paths := append([]string{}, g.imports.paths...)
+	sort.Strings(paths)
+	std, others := "", ""
+	for _, i := range paths {
+		if strings.Contains(strings.Split(i, "/")[0], ".") {
+			others += g.imports.spec(i) + "\n"
+		} else {
+			std += g.imports.spec(i) + "\n"
+		}
+	}
+	return std + "\n" + others
+}
+
+// returns the src of the client method calling the route, a route having a param whose type cannot be
+// used outside of its pkg is left out with a comment saying why
+func (g *clientGen) method(name string, route *Route) string {
+	args := []string{"ctx context.Context"}
+	argNames := map[*Param]string{}
+	// the imports of the params are kept only when the method is not left out
+	imports := g.imports.clone()
+	for _, param := range route.Params {
+		paramType, err := g.m.exportedTypeSrc(route.Pkg, route.File, param.typeExpr, imports)
+		if err != nil {
+			return fmt.Sprintf("\n// %v is left out as param '%v' of %v cannot be used by the client: %v\n", name, param.Name, route.Handler, err)
+		}
+		argName := param.Name
+		if contains(clientLocals, argName) {
+			argName += "Param"
+		}
+		argNames[param] = argName
+		args = append(args, argName+" "+paramType)
+	}
+	g.imports = imports
+
+	doc := ""
+	if route.Doc != "" {
+		doc = "// " + strings.ReplaceAll(route.Doc, "\n", "\n// ") + "\n//\n"
+	}
+	doc += fmt.Sprintf("// %v calls %v %v\n", name, route.Method, route.Path)
+	if route.Deprecated {
+		doc += "//\n// Deprecated: the route is deprecated\n"
+	}
+
+	body := ""
+	query := "nil"
+	for _, param := range route.Params {
+		if param.location() != InQuery {
+			continue
+		}
+		if query == "nil" {
+			g.requireImport("net/url")
+			body += "query := url.Values{}\n"
+			query = "query"
+		}
+		if param.Required {
+			body += fmt.Sprintf("query.Set(%q, web.FormatParam(%v))\n", param.Name, argNames[param])
+			continue
+		}
+		body += fmt.Sprintf(`if %[2]v != nil {
+			query.Set(%[1]q, web.FormatParam(*%[2]v))
+		}
+		`, param.Name, argNames[param])
+	}
+	requestBody := "nil"
+	for _, param := range route.Params {
+		if param.location() != InBody {
+			continue
+		}
+		requestBody = argNames[param]
+		if !param.Required {
+			// a nil pointer in an interface is not nil, so the body is left out explicitly
+			body += fmt.Sprintf(`var body any
+			if %[1]v != nil {
+				body = %[1]v
+			}
+			`, argNames[param])
+			requestBody = "body"
+		}
+	}
+	pathSrc := g.pathSrc(route, argNames)
+
+	call := fmt.Sprintf("resp, err := c.Do(ctx, %q, %v, %v, %v)", route.Method, pathSrc, query, requestBody)
+	response := route.Response
+	if len(response.Results) == 0 {
+		return fmt.Sprintf(`
+		%vfunc (c *Client) %v(%v) error {
+			%v%v
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			return nil
+		}
+		`, doc, name, strings.Join(args, ", "), body, call)
+	}
+	result := response.Results[0]
+	// the imports of the result type are kept only when it is used
+	resultImports := g.imports.clone()
+	resultType, err := g.m.exportedTypeSrc(route.Pkg, route.File, result.typeExpr, resultImports)
+	isJSON := isJSONContentType(response.ContentType)
+	decode := ""
+	switch {
+	case err != nil && isJSON:
+		// the result is left undecoded for the caller
+		resultType, resultImports = "json.RawMessage", g.imports
+		resultImports.add("encoding/json", "json")
+		decode = "err = web.DecodeJSON(resp, &result)"
+	case err != nil:
+		resultType, resultImports = "[]byte", g.imports
+		decode = "result, err = web.ReadBody(resp)"
+	case isJSON:
+		decode = "err = web.DecodeJSON(resp, &result)"
+	case resultType == "[]byte":
+		decode = "result, err = web.ReadBody(resp)"
+	default:
+		// the app writes anything else using fmt.Fprint, so only a string can be read back as is
+		resultType, resultImports = "string", g.imports
+		decode = `text, err := web.ReadBody(resp)
+		result = string(text)`
+	}
+	g.imports = resultImports
+	return fmt.Sprintf(`
+	%vfunc (c *Client) %v(%v) (%v, error) {
+		var result %v
+		%v%v
+		if err != nil {
+			return result, err
+		}
+		%v
+		return result, err
+	}
+	`, doc, name, strings.Join(args, ", "), resultType, resultType, body, call, decode)
+}
+
+// returns the src of the path of the route having its wildcards replaced by the args
+func (g *clientGen) pathSrc(route *Route, argNames map[*Param]string) string {
+	parts := []string{}
+	literal := ""
+	for i, segment := range strings.Split(route.Path, "/") {
+		if i > 0 {
+			literal += "/"
+		}
+		if !isWildcard(segment) {
+			literal += segment
+			continue
+		}
+		parts = append(parts, strconv.Quote(literal))
+		literal = ""
+		param := findParam(route.Params, segment[1:])
+		arg := argNames[param]
+		if strings.HasPrefix(segment, "*") {
+			if param.Type != "string" {
+				// the type of a catch-all has the kind string
+				arg = fmt.Sprintf("string(%v)", arg)
+			}
+			parts = append(parts, fmt.Sprintf("web.EscapeCatchAll(%v)", arg))
+			continue
+		}
+		g.requireImport("net/url")
+		parts = append(parts, fmt.Sprintf("url.PathEscape(web.FormatParam(%v))", arg))
+	}
+	if literal != "" {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, "+")
+}
+
+// returns the type expr as written in the file of the pkg qualified to be used from another pkg, adding the pkgs
+// it refers to to the imports, types not exported by their pkg cannot be used
+func (m *Matte) exportedTypeSrc(pkg *Pkg, file *ast.File, expr ast.Expr, imports *importSet) (string, error) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if _, isBuiltin := types.Universe.Lookup(expr.Name).(*types.TypeName); isBuiltin {
+			return expr.Name, nil
+		}
+		if !expr.IsExported() || pkg.Name == "main" {
+			return "", fmt.Errorf("type %v is not exported by pkg %v", expr.Name, pkg.Name)
+		}
+		return imports.add(pkg.ImportPath, pkg.Name) + "." + expr.Name, nil
+	case *ast.SelectorExpr:
+		pkgIdent, ok := expr.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		importPath, ok := m.importPathOf(file, pkgIdent.Name)
+		if !ok {
+			return "", fmt.Errorf("package %v of type %v is not imported", pkgIdent.Name, types.ExprString(expr))
+		}
+		return imports.add(importPath, pkgIdent.Name) + "." + expr.Sel.Name, nil
+	case *ast.StarExpr:
+		src, err := m.exportedTypeSrc(pkg, file, expr.X, imports)
+		return "*" + src, err
+	case *ast.ArrayType:
+		src, err := m.exportedTypeSrc(pkg, file, expr.Elt, imports)
+		if expr.Len != nil {
+			return "[" + types.ExprString(expr.Len) + "]" + src, err
+		}
+		return "[]" + src, err
+	case *ast.MapType:
+		keySrc, err := m.exportedTypeSrc(pkg, file, expr.Key, imports)
+		if err != nil {
+			return "", err
+		}
+		valueSrc, err := m.exportedTypeSrc(pkg, file, expr.Value, imports)
+		return "map[" + keySrc + "]" + valueSrc, err
+	case *ast.InterfaceType:
+		if expr.Methods == nil || len(expr.Methods.List) == 0 {
+			return "any", nil
+		}
+	}
+	return "", fmt.Errorf("type %v cannot be used outside of its pkg", types.ExprString(expr))
+}
+
+// writes the go client into the client dir of the matte dir
+func (m *Matte) writeClient() error {
+	src, err := m.ClientSrc()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(m.matteDir, ClientDir)
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return fmt.Errorf("unable to mkdir %v due to err: %v", dir, err)
+	}
+	err = os.WriteFile(filepath.Join(dir, ClientFile), src, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", ClientFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildWritesClient(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+import "github.com/ondbyte/test/models"
+
+// Get returns the user
+// @path("GET","/users/:id/files/*rest")
+// @query("verbose","ctx")
+func Get(id int, rest string, verbose bool, ctx *string) (*models.User, error) { return nil, nil }
+
+// @path("PUT","/users/:id")
+// @body("u")
+func Update(id int, u *models.User) error { return nil }
+
+// @path("GET","/users/:id/name")
+// @produces("text/plain")
+func Name(id int) string { return "" }
+
+type role string
+
+// @path("GET","/roles")
+// @query("r")
+func Roles(r role) []string { return nil }
+`,
+		"orders/orders.go": `
+package orders
+
+// @path("GET","/orders/:id")
+// @deprecated()
+func Get(id string) map[string]any { return nil }
+
+type Path string
+
+// @path("GET","/archive/*path")
+func Archived(path Path) []byte { return nil }
+`,
+		"models/models.go": `
+package models
+
+type User struct{ Name string }
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	src, err := os.ReadFile(filepath.Join(dir, matte.MatteDir, matte.ClientDir, matte.ClientFile))
+	if !assert.NoError(err) {
+		return
+	}
+	client := string(src)
+	for _, s := range []string{
+		`"github.com/ondbyte/test/models"`,
+		`// Get returns the user
+//
+// UsersGet calls GET /users/:id/files/*rest
+func (c *Client) UsersGet(ctx context.Context, id int, rest string, verbose bool, ctxParam *string) (*models.User, error) {`,
+		`query.Set("verbose", web.FormatParam(verbose))
+	if ctxParam != nil {
+		query.Set("ctx", web.FormatParam(*ctxParam))
+	}
+	resp, err := c.Do(ctx, "GET", "/users/"+url.PathEscape(web.FormatParam(id))+"/files/"+web.EscapeCatchAll(rest), query, nil)`,
+		`func (c *Client) Update(ctx context.Context, id int, u *models.User) error {
+	var body any
+	if u != nil {
+		body = u
+	}
+	resp, err := c.Do(ctx, "PUT", "/users/"+url.PathEscape(web.FormatParam(id)), nil, body)`,
+		`func (c *Client) Name(ctx context.Context, id int) (string, error) {`,
+		`text, err := web.ReadBody(resp)`,
+		`// Roles is left out as param 'r' of users.Roles cannot be used by the client: type role is not exported by pkg users`,
+		`resp, err := c.Do(ctx, "GET", "/archive/"+web.EscapeCatchAll(string(path)), nil, nil)`,
+		`// Deprecated: the route is deprecated
+func (c *Client) OrdersGet(ctx context.Context, id string) (map[string]any, error) {`,
+	} {
+		assert.Contains(client, s)
+	}
+}
+
+func TestClientMethodsOfAHandlerHavingSeveralRoutes(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+// @Param   q  query  string  false  "name to search"
+// @Router  /users [get]
+// @Router  /v2/users/all [get]
+func List(q *string) []string { return nil }
+`,
+		"orders/orders.go": `
+package orders
+
+// @path("GET","/orders")
+func List() []string { return nil }
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	src, err := os.ReadFile(filepath.Join(dir, matte.MatteDir, matte.ClientDir, matte.ClientFile))
+	if !assert.NoError(err) {
+		return
+	}
+	client := string(src)
+	assert.Contains(client, "func (c *Client) UsersListGetUsers(ctx context.Context, q *string) ([]string, error) {")
+	assert.Contains(client, "func (c *Client) UsersListGetV2UsersAll(ctx context.Context, q *string) ([]string, error) {")
+	assert.Contains(client, "func (c *Client) OrdersList(ctx context.Context) ([]string, error) {")
+}
+
+func TestClientImportsPkgsHavingTheSameName(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"models/v2/models.go": `
+package models
+
+type User struct{ Name string }
+`,
+		"other/models/models.go": `
+package models
+
+type Meta struct{ Tag string }
+`,
+		"users/users.go": `
+package users
+
+import (
+	m "github.com/ondbyte/test/models/v2"
+	"github.com/ondbyte/test/other/models"
+)
+
+// @path("PUT","/users")
+// @body("u")
+func Update(u m.User) models.Meta { return models.Meta{} }
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	src, err := os.ReadFile(filepath.Join(dir, matte.MatteDir, matte.ClientDir, matte.ClientFile))
+	if !assert.NoError(err) {
+		return
+	}
+	client := string(src)
+	assert.Contains(client, `m "github.com/ondbyte/test/models/v2"`)
+	assert.Contains(client, `"github.com/ondbyte/test/other/models"`)
+	assert.Contains(client, "func (c *Client) Update(ctx context.Context, u m.User) (models.Meta, error) {")
+}
@@ -1 +1,258 @@
 package matte
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ondbyte/matte/config"
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+//go:generate go run github.com/traefik/yaegi/cmd/yaegi extract -name matte github.com/ondbyte/matte/config
+
+// Symbols are the pkgs MatteApp can import besides the std lib, as exported for yaegi
+var Symbols = map[string]map[string]reflect.Value{}
+
+const configImportPath = "github.com/ondbyte/matte/config"
+
+const configFuncHint = `ex: func MatteApp() config.Config { c := config.Default(); c.Addr = ":9000"; return c }, run matte configure to write one`
+
+// returns the MatteApp func of the core pkg along with the file declaring it, nil if the project has none
+func (m *Matte) configFunc() (*ast.FuncDecl, *ast.File) {
+	if m.corePkg == nil {
+		return nil, nil
+	}
+	fileNames := make([]string, 0, len(m.corePkg.Files))
+	for fileName := range m.corePkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		file := m.corePkg.Files[fileName]
+		for _, decl := range file.Decls {
+			fnDecl, ok := decl.(*ast.FuncDecl)
+			if ok && fnDecl.Recv == nil && fnDecl.Name.Name == GeneralApiInfoFuncName {
+				return fnDecl, file
+			}
+		}
+	}
+	return nil, nil
+}
+
+// returns the name the import is referred to with in its file
+func importName(i *ast.ImportSpec) string {
+	importPath, _ := strconv.Unquote(i.Path.Value)
+	if i.Name != nil {
+		return i.Name.Name
+	}
+	return path.Base(importPath)
+}
+
+// gets the src of a program declaring the function used to configure the project, along with the imports of its file
+// it uses, the function is copied as is so the lines of the program can be mapped back to the file
+func (m *Matte) GetConfigFuncSrc(fnDecl *ast.FuncDecl, file *ast.File) (src string, fnLine int, err error) {
+	used := map[string]bool{}
+	ast.Inspect(fnDecl, func(n ast.Node) bool {
+		if selector, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := selector.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	imports := ""
+	for _, i := range file.Imports {
+		if !used[importName(i)] {
+			continue
+		}
+		importPath, _ := strconv.Unquote(i.Path.Value)
+		isStd := !strings.Contains(strings.Split(importPath, "/")[0], ".")
+		if !isStd && importPath != configImportPath {
+			return "", 0, errorAt(i.Pos(), "", "move what is required from it into "+GeneralApiInfoFuncName,
+				"%v can only use the std lib and %v, but uses %v", GeneralApiInfoFuncName, configImportPath, importPath)
+		}
+		if i.Name != nil {
+			imports += i.Name.Name + " "
+		}
+		imports += i.Path.Value + "\n"
+	}
+	position := m.fileSet.Position(fnDecl.Pos())
+	fileSrc, err := os.ReadFile(position.Filename)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file %v due to err: %v", position.Filename, err)
+	}
+	end := m.fileSet.Position(fnDecl.End())
+	src = fmt.Sprintf("package main\n\nimport (\n%v)\n\n", imports)
+	fnLine = strings.Count(src, "\n") + 1
+	return src + string(fileSrc[position.Offset:end.Offset]) + "\n", fnLine, nil
+}
+
+// file:line:col: msg, as printed by yaegi for an error in the src it evaluates
+var interpreterError = regexp.MustCompile(`^(?:\S+?:)?(\d+):(\d+): (.*)$`)
+
+// evaluates the MatteApp func of the project using yaegi and uses the config.Config it returns,
+// a project without one, or with one returning nothing as it only has the general api info, uses the defaults
+func (m *Matte) LoadConfig() error {
+	fnDecl, file := m.configFunc()
+	if fnDecl == nil {
+		return nil
+	}
+	results := fnDecl.Type.Results
+	if fnDecl.Type.Params.NumFields() > 0 {
+		return m.resolve(errorAt(fnDecl.Type.Params.Pos(), "", configFuncHint, "%v must take no params", GeneralApiInfoFuncName))
+	}
+	if results.NumFields() == 0 {
+		return nil
+	}
+	if !m.returnsConfig(fnDecl, file) {
+		resultsSrc, _ := m.StringifyTheAstNode(results.List[0].Type)
+		if results.NumFields() > 1 {
+			resultsSrc = fmt.Sprintf("%v results", results.NumFields())
+		}
+		return m.resolve(errorAt(results.Pos(), "", configFuncHint,
+			"%v must return config.Config of %v, but returns %v", GeneralApiInfoFuncName, configImportPath, resultsSrc))
+	}
+	if m.configSource != "" {
+		return m.resolve(errorAt(fnDecl.Name.Pos(), "", "remove one of them, or return nothing from "+GeneralApiInfoFuncName+" to use it only for the general api info",
+			"the config is set by both %v and %v", m.configSource, GeneralApiInfoFuncName))
+	}
+	src, fnLine, err := m.GetConfigFuncSrc(fnDecl, file)
+	if err != nil {
+		return m.resolve(err)
+	}
+	// yaegi prints the position of a panic into stderr
+	stderr := &strings.Builder{}
+	i := interp.New(interp.Options{Env: os.Environ(), Stdout: os.Stdout, Stderr: stderr})
+	err = i.Use(stdlib.Symbols)
+	if err == nil {
+		err = i.Use(Symbols)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to load the symbols of the interpreter due to err: %v", err)
+	}
+	_, err = i.Eval(src)
+	if err != nil {
+		return m.interpreterError(fnDecl, fnLine, err)
+	}
+	fn, err := i.Eval("main." + GeneralApiInfoFuncName)
+	if err != nil {
+		return m.interpreterError(fnDecl, fnLine, err)
+	}
+	c, err := callConfigFunc(fn)
+	if err != nil {
+		return m.interpreterError(fnDecl, fnLine, fmt.Errorf("%v%v", stderr, err))
+	}
+	m.config = c
+	m.configSource = m.fileSet.Position(fnDecl.Pos()).Filename
+	return checkConfig(c, m.fileSet.Position(fnDecl.Pos()))
+}
+
+// whether the func returns config.Config, as imported by its file
+func (m *Matte) returnsConfig(fnDecl *ast.FuncDecl, file *ast.File) bool {
+	results := fnDecl.Type.Results
+	if results.NumFields() != 1 {
+		return false
+	}
+	selector, ok := results.List[0].Type.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Config" {
+		return false
+	}
+	x, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	for _, i := range file.Imports {
+		if i.Path.Value == strconv.Quote(configImportPath) && importName(i) == x.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// calls the evaluated MatteApp, a panic of it is returned as an error
+func callConfigFunc(fn reflect.Value) (c config.Config, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v panicked: %v", GeneralApiInfoFuncName, r)
+		}
+	}()
+	if fn.Kind() != reflect.Func {
+		return c, fmt.Errorf("%v is not a func", GeneralApiInfoFuncName)
+	}
+	results := fn.Call(nil)
+	if len(results) != 1 {
+		return c, fmt.Errorf("%v must return a single config.Config", GeneralApiInfoFuncName)
+	}
+	c, ok := results[0].Interface().(config.Config)
+	if !ok {
+		return c, fmt.Errorf("%v returned %T instead of config.Config", GeneralApiInfoFuncName, results[0].Interface())
+	}
+	return c, nil
+}
+
+// maps an error of yaegi in the src of the program back to the file declaring MatteApp
+func (m *Matte) interpreterError(fnDecl *ast.FuncDecl, fnLine int, err error) error {
+	msg := strings.TrimSpace(err.Error())
+	if panicErr, ok := err.(interp.Panic); ok {
+		msg = fmt.Sprintf("%v panicked: %v", GeneralApiInfoFuncName, panicErr.Value)
+	}
+	d := &Diagnostic{Msg: msg, Position: m.fileSet.Position(fnDecl.Pos())}
+	// a panic is printed as "line:col: panic" followed by the error
+	lines := strings.Split(msg, "\n")
+	if match := interpreterError.FindStringSubmatch(lines[0]); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		column, _ := strconv.Atoi(match[2])
+		if line >= fnLine {
+			d.Position.Line += line - fnLine
+			d.Position.Column = column
+			d.Position.Offset = 0
+		}
+		d.Msg = strings.Join(lines[1:], "\n")
+		if match[3] != "panic" {
+			d.Msg = fmt.Sprintf("unable to evaluate %v due to err: %v", GeneralApiInfoFuncName, match[3])
+			d.Hint = fmt.Sprintf("%v can only use the std lib and %v", GeneralApiInfoFuncName, configImportPath)
+		}
+	}
+	return d
+}
+
+// verifies the config can be used to generate the app, position is of the src configuring it
+func checkConfig(c config.Config, position token.Position) error {
+	diagnostics := Diagnostics{}
+	add := func(hint, format string, args ...any) {
+		d := &Diagnostic{Position: position, Msg: fmt.Sprintf(format, args...), Hint: hint}
+		if !position.IsValid() {
+			d.Msg = position.Filename + ": " + d.Msg
+		}
+		diagnostics.Add(d)
+	}
+	if c.Framework != config.HTTPRouter {
+		add("use "+config.HTTPRouter+", or leave it as set by default",
+			"framework %v is not supported, only %v is", c.Framework, config.HTTPRouter)
+	}
+	if c.Addr == "" {
+		add(`ex: ":8000"`, "addr the app listens on is empty")
+	}
+	if c.OutputDir == "" || c.OutputDir == "." || !filepath.IsLocal(c.OutputDir) {
+		add(`ex: "matte" or "gen/app"`, "output dir %q must be a dir inside the project", c.OutputDir)
+	}
+	for _, dir := range c.Exclude {
+		if !filepath.IsLocal(dir) {
+			add(`ex: "internal/legacy"`, "excluded dir %q must be relative to the root of the project", dir)
+		}
+	}
+	if c.Docs.Enabled && !c.Outputs.OpenAPI {
+		add("disable the docs too, or enable the openapi output", "the docs serve the openapi document, so its output cannot be disabled while they are enabled")
+	}
+	return diagnostics.Err()
+}
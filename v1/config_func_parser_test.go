@@ -0,0 +1,74 @@
+package matte_test
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfigEvaluatesMatteApp(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"config.matte.go": `package main
+
+import (
+	"fmt"
+	"os"
+
+	mc "github.com/ondbyte/matte/config"
+)
+
+// @title users api
+func MatteApp() mc.Config {
+	c := mc.Default()
+	c.Addr = fmt.Sprintf(":%v", os.Getenv("USERS_PORT"))
+	c.Docs.Enabled = false
+	c.Outputs.TypeScriptClient = false
+	return c
+}
+`,
+		"users/users.go": usersPkg,
+	})
+	t.Setenv("USERS_PORT", "9000")
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	app := readApp(t, dir)
+	assert.Contains(app, `addr = ":9000"`)
+	assert.NotContains(app, "web.EnabledIn(")
+	assert.NoFileExists(filepath.Join(dir, matte.MatteDir, matte.ClientTSFile))
+	assert.FileExists(filepath.Join(dir, matte.MatteDir, matte.ClientDir, matte.ClientFile))
+
+	for _, c := range []struct {
+		name, src, expected string
+	}{
+		{"panic", `func MatteApp() config.Config {
+	panic("no config")
+}`, "config.matte.go:6:2: MatteApp panicked: no config"},
+		{"undefined", `func MatteApp() config.Config {
+	c := config.Default()
+	c.Addr = port
+	return c
+}`, "config.matte.go:7:11: unable to evaluate MatteApp due to err: undefined: port"},
+		{"result", `func MatteApp() int {
+	return 0
+}`, "config.matte.go:5:17: MatteApp must return config.Config of github.com/ondbyte/matte/config, but returns int"},
+		{"framework", `func MatteApp() config.Config {
+	c := config.Default()
+	c.Framework = "gin"
+	return c
+}`, "config.matte.go:5:1: framework gin is not supported, only httprouter is"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			writeFiles(t, dir, map[string]string{"config.matte.go": "package main\n\nimport \"github.com/ondbyte/matte/config\"\n\n" + c.src + "\n"})
+			_, err := matte.Load(token.NewFileSet(), dir)
+			if a.Error(t, err) {
+				a.Contains(t, err.Error(), c.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package matte
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ondbyte/matte/config"
+)
+
+// declarative config of the project, at its root, used instead of the MatteApp func
+const ConfigTOMLFile = "matte.toml"
+
+const configTOMLHint = `ex: addr = ":9000", or [env.production] addr = ":80" to override it in production, see the toml tags of config.Config for every key`
+
+// layout of matte.toml, the env table has the overrides of each environment, ex: [env.production]
+type tomlConfig struct {
+	config.Config
+	Env map[string]toml.Primitive `toml:"env"`
+}
+
+// loads matte.toml of the project over the default config, along with the overrides of the env of m,
+// the config is left as is if the project has none
+func (m *Matte) loadTOMLConfig() error {
+	tomlPath := filepath.Join(m.wd, ConfigTOMLFile)
+	if _, err := os.Stat(tomlPath); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read %v due to err: %v", ConfigTOMLFile, err)
+	}
+	tc := tomlConfig{Config: m.config}
+	md, err := toml.DecodeFile(tomlPath, &tc)
+	if err != nil {
+		return tomlError(tomlPath, err)
+	}
+	c := tc.Config
+	envs := make([]string, 0, len(tc.Env))
+	for env := range tc.Env {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	// every env is decoded so a mistake in any of them is reported, not only in the one being built
+	for _, env := range envs {
+		envConfig := tc.Config
+		envConfig.Exclude = append([]string{}, tc.Exclude...)
+		envConfig.Docs.Environments = append([]string{}, tc.Docs.Environments...)
+		err = md.PrimitiveDecode(tc.Env[env], &envConfig)
+		if err != nil {
+			return tomlError(tomlPath, fmt.Errorf("invalid env %v: %w", env, err))
+		}
+		if env == m.env {
+			c = envConfig
+		}
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		return &Diagnostic{Position: token.Position{Filename: tomlPath}, Hint: configTOMLHint,
+			Msg: fmt.Sprintf("%v has unknown keys: %v", tomlPath, strings.Join(keys, ", "))}
+	}
+	m.config = c
+	m.configSource = tomlPath
+	return checkConfig(c, token.Position{Filename: tomlPath})
+}
+
+// returns a Diagnostic at the line of a toml error, if it has one
+func tomlError(tomlPath string, err error) error {
+	d := &Diagnostic{Position: token.Position{Filename: tomlPath}, Hint: configTOMLHint}
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		d.Position.Line = parseErr.Position.Line
+		d.Msg = parseErr.Message
+		if d.Msg == "" {
+			_, d.Msg, _ = strings.Cut(strings.TrimPrefix(parseErr.Error(), "toml: "), ": ")
+		}
+		d.Msg = "invalid " + ConfigTOMLFile + ": " + d.Msg
+		return d
+	}
+	if match := tomlValueError.FindStringSubmatch(err.Error()); match != nil {
+		d.Position.Line, _ = strconv.Atoi(match[1])
+		d.Msg = fmt.Sprintf("invalid value of %v: %v", match[2], match[3])
+		return d
+	}
+	d.Msg = fmt.Sprintf("invalid %v: %v", tomlPath, strings.TrimPrefix(err.Error(), "toml: "))
+	return d
+}
+
+// error of a value which cannot be decoded into its field, as printed by toml
+var tomlValueError = regexp.MustCompile(`line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// EffectiveConfig returns the config the project is built with in the env, ie: the defaults overridden by its
+// matte.toml or the config returned by its MatteApp func, along with the path of the file it is read from, empty
+// if the defaults are used
+func EffectiveConfig(fileSet *token.FileSet, project, env string) (config.Config, string, error) {
+	m, err := loadConfigured(fileSet, project, env)
+	if err != nil {
+		return config.Config{}, "", err
+	}
+	return m.config, m.configSource, nil
+}
@@ -0,0 +1,113 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	"github.com/ondbyte/matte/web"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestConfigTOML(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"matte.toml": `
+addr = ":9000"
+output_dir = "gen/app"
+exclude = ["legacy"]
+
+[outputs]
+typescript_client = false
+
+[env.production]
+addr = ":80"
+
+[env.production.docs]
+enabled = false
+`,
+		"users/users.go":   usersPkg,
+		"legacy/legacy.go": "package legacy\n\nfunc broken( {\n",
+	})
+	t.Setenv(web.EnvVar, "development")
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	outputDir := filepath.Join(dir, "gen", "app")
+	app, _ := os.ReadFile(filepath.Join(outputDir, matte.AppFile))
+	assert.Contains(string(app), `addr = ":9000"`)
+	assert.Contains(string(app), "web.SpecHandler(openAPISpec)")
+	assert.FileExists(filepath.Join(outputDir, matte.OpenAPIJSONFile))
+	assert.FileExists(filepath.Join(outputDir, matte.ClientDir, matte.ClientFile))
+	assert.NoFileExists(filepath.Join(outputDir, matte.ClientTSFile))
+	assert.NoDirExists(filepath.Join(dir, matte.MatteDir))
+
+	c, source, err := matte.EffectiveConfig(token.NewFileSet(), dir, "production")
+	if assert.NoError(err) {
+		assert.Equal(filepath.Join(dir, matte.ConfigTOMLFile), source)
+		assert.Equal(":80", c.Addr)
+		assert.False(c.Docs.Enabled)
+		assert.Equal("/docs", c.Docs.UIPath)
+		assert.Equal([]string{"legacy"}, c.Exclude)
+		assert.False(c.Outputs.TypeScriptClient)
+	}
+	// the app is built for production unless MATTE_ENV says otherwise, same as it runs
+	t.Setenv(web.EnvVar, "")
+	err = matte.Build(token.NewFileSet(), dir)
+	if assert.NoError(err) {
+		app, _ = os.ReadFile(filepath.Join(outputDir, matte.AppFile))
+		assert.Contains(string(app), `addr = ":80"`)
+		assert.NotContains(string(app), "openAPISpec")
+	}
+
+	os.WriteFile(filepath.Join(dir, matte.ConfigTOMLFile), []byte("addr = \":9000\"\n"), 0666)
+	os.WriteFile(filepath.Join(dir, "config.matte.go"), []byte(`package main
+
+import "github.com/ondbyte/matte/config"
+
+func MatteApp() config.Config { return config.Default() }
+`), 0666)
+	_, _, err = matte.EffectiveConfig(token.NewFileSet(), dir, "")
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "config.matte.go:5:6: the config is set by both "+filepath.Join(dir, matte.ConfigTOMLFile)+" and MatteApp")
+	}
+	err = matte.Configure(dir, matte.ConfigureOptions{Force: true})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "the project is configured by its matte.toml")
+	}
+}
+
+func TestConfigTOMLErrors(t *testing.T) {
+	for _, c := range []struct {
+		name, toml, expected string
+	}{
+		{"value", "addr = \":9000\"\n\n[env.production]\naddr = 80\n", "matte.toml:4: invalid value of env.production.addr: incompatible types"},
+		{"unknown key", "adr = \":9000\"\n", "matte.toml has unknown keys: adr"},
+		{"syntax", "addr = \n", "invalid matte.toml: expected value"},
+		{"output dir", "output_dir = \"../app\"\n", `matte.toml: output dir "../app" must be a dir inside the project`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			dir := newTestProject(t, map[string]string{matte.ConfigTOMLFile: c.toml, "users/users.go": usersPkg})
+			_, _, err := matte.EffectiveConfig(token.NewFileSet(), dir, "")
+			if a.Error(t, err) {
+				a.Contains(t, err.Error(), c.expected)
+			}
+		})
+	}
+}
+
+func TestConfigTOMLWhichCannotBeRead(t *testing.T) {
+	dir := newTestProject(t, map[string]string{"users/users.go": usersPkg})
+	// a symlink to itself cannot be stat'd, but it exists
+	err := os.Symlink(matte.ConfigTOMLFile, filepath.Join(dir, matte.ConfigTOMLFile))
+	if err != nil {
+		t.Skip("symlinks are not supported")
+	}
+	_, _, err = matte.EffectiveConfig(token.NewFileSet(), dir, "")
+	if a.Error(t, err) {
+		a.Contains(t, err.Error(), "unable to read matte.toml due to err: ")
+	}
+}
@@ -1,85 +1,209 @@
 package matte
 
 import (
+	"bufio"
 	"fmt"
 	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/ondbyte/matte/config"
 )
 
-// configures your project
-// this creates config.matte.go
-func (m *Matte) Configure(wd string) error {
-	_, err := GetPackagePathFromGoMod(wd)
+// file written by Configure into the root of the project
+const ConfigFile = "config.matte.go"
+
+// ConfigureOptions are the answers Configure writes into the config file, empty ones are asked for
+// when In is set, the defaults are used otherwise
+type ConfigureOptions struct {
+	// overwrite the config file if it exists
+	Force bool
+	// title of the api, the name of the module by default
+	Title string
+	// version of the api, 0.0.0 by default
+	Version string
+	// address the app listens on, :8000 by default
+	Addr string
+	// answers of the questions are read from In, nil means non interactive
+	In io.Reader
+	// questions are written into Out
+	Out io.Writer
+}
+
+// Configure writes a config file having a MatteApp func which returns the default config
+// into the root of the project, so it can be edited from there
+func Configure(project string, options ConfigureOptions) error {
+	modulePath, err := GetPackagePathFromGoMod(project)
 	if err != nil {
 		return err
 	}
-	return nil
-}
+	configPath := filepath.Join(project, ConfigFile)
+	if _, err := os.Stat(configPath); err == nil && !options.Force {
+		return fmt.Errorf("%v already exists, use force to overwrite it", configPath)
+	}
+	if _, err := os.Stat(filepath.Join(project, ConfigTOMLFile)); err == nil {
+		return fmt.Errorf("the project is configured by its %v, so %v is not written", ConfigTOMLFile, ConfigFile)
+	}
+	pkgName, declaredIn, err := corePkgOf(project)
+	if err != nil {
+		return err
+	}
+	if declaredIn != "" && filepath.Base(declaredIn) != ConfigFile {
+		return fmt.Errorf("%v is already declared in %v", GeneralApiInfoFuncName, declaredIn)
+	}
 
-func (m *Matte) StringifyTheAstNode(node ast.Node) (string, error) {
-	strBuilder := strings.Builder{}
-	err := format.Node(&strBuilder, m.fileSet, node)
+	defaults := config.Default()
+	answers := struct {
+		title, version, addr             string
+		docs, goClient, typeScriptClient bool
+	}{options.Title, options.Version, options.Addr, defaults.Docs.Enabled, defaults.Outputs.GoClient, defaults.Outputs.TypeScriptClient}
+	q := &questions{out: options.Out}
+	if options.In != nil {
+		q.in = bufio.NewScanner(options.In)
+	}
+	if answers.title == "" {
+		answers.title = q.ask("title of the api", path.Base(modulePath))
+	}
+	if answers.version == "" {
+		answers.version = q.ask("version of the api", "0.0.0")
+	}
+	if answers.addr == "" {
+		answers.addr = q.ask("address the app listens on", defaults.Addr)
+	}
+	answers.docs = q.confirm("serve the openapi document and the api explorer", answers.docs)
+	answers.goClient = q.confirm("generate a go client", answers.goClient)
+	answers.typeScriptClient = q.confirm("generate a typescript client", answers.typeScriptClient)
+
+	environments := ""
+	for _, env := range defaults.Docs.Environments {
+		environments += strconv.Quote(env) + ", "
+	}
+	src := fmt.Sprintf(`package %v
+
+import "github.com/ondbyte/matte/config"
+
+// %v configures the app generated by matte, the swag annotations of its doc describe the api
+//
+// @title   %v
+// @version %v
+func %v() config.Config {
+	c := config.Default()
+	// address the app listens on
+	c.Addr = %q
+	// router of the generated app, only httprouter is supported for now
+	c.Framework = config.HTTPRouter
+	// dir the app is generated into, and dirs which are not scanned for handlers, relative to the root of the project
+	c.OutputDir = %q
+	c.Exclude = []string{}
+	// the openapi document and the api explorer, served only when MATTE_ENV is one of the environments
+	c.Docs.Enabled = %v
+	c.Docs.SpecPath = %q
+	c.Docs.UIPath = %q
+	c.Docs.Environments = []string{%v}
+	// path the title and version of the app are served at, empty disables it
+	c.VersionPath = %q
+	// files generated into the output dir besides the app, the docs serve the openapi document
+	c.Outputs.OpenAPI = %v
+	c.Outputs.GoClient = %v
+	c.Outputs.TypeScriptClient = %v
+	return c
+}
+`, pkgName, GeneralApiInfoFuncName, answers.title, answers.version, GeneralApiInfoFuncName,
+		answers.addr, defaults.OutputDir, answers.docs, defaults.Docs.SpecPath, defaults.Docs.UIPath, strings.TrimSuffix(environments, ", "),
+		defaults.VersionPath, defaults.Outputs.OpenAPI, answers.goClient, answers.typeScriptClient)
+	formatted, err := format.Source([]byte(src))
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to format %v due to err: %v", ConfigFile, err)
 	}
-	return strBuilder.String(), nil
+	err = os.WriteFile(configPath, formatted, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", configPath, err)
+	}
+	return nil
 }
 
-// gets the src string of the function used to configure the project
-func (m *Matte) GetConfigFuncSrc() (string, error) {
-	importStmt := &ast.ImportSpec{Path: &ast.BasicLit{Value: "github.com/ondbyte/matte/frameworks/config"}}
-	corePkg := m.corePkg
-	if corePkg == nil {
-		return "", fmt.Errorf("corePkg should be non nil")
-	}
-	for _, file := range corePkg.Files {
-		for _, i := range file.Imports {
-			if i.Path.Value == importStmt.Path.Value {
-				importStmt = i
-			}
+// returns the name of the pkg at the root of the project, main if it has none yet, along with the
+// file declaring the MatteApp func, if any
+func corePkgOf(project string) (pkgName, declaredIn string, err error) {
+	pkgName = "main"
+	entries, err := os.ReadDir(project)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read dir %v due to err: %v", project, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
 		}
+		filePath := filepath.Join(project, entry.Name())
+		file, err := parser.ParseFile(token.NewFileSet(), filePath, nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		pkgName = file.Name.Name
 		for _, decl := range file.Decls {
-			decl, ok := decl.(*ast.FuncDecl)
-			if !ok {
-				continue
-			}
-			if decl.Name.Name == GeneralApiInfoFuncName {
-				if importStmt == nil {
-					return "", fmt.Errorf(
-						`only a configuration function with name "%v" should exists, no other function with the name "%v" should exist`,
-						GeneralApiInfoFuncName, GeneralApiInfoFuncName)
-				}
-				strAstNode, err := m.StringifyTheAstNode(decl)
-				if err != nil {
-					return "", err
-				}
-				return fmt.Sprintf("import %v\n\n%v", importStmt.Path.Value, strAstNode), nil
+			if fnDecl, ok := decl.(*ast.FuncDecl); ok && fnDecl.Recv == nil && fnDecl.Name.Name == GeneralApiInfoFuncName {
+				declaredIn = filePath
 			}
 		}
 	}
-	return "",
-		fmt.Errorf(`a function named %v should be present in your project,
-		which should return a slew of configurations specific to frameworks you use in your project.
-		handlers will be parsed based on the configuration.
-		for example,
-		func %v() (frameworks.HttpConfig, frameworks.GinConfig, ...){
-			...
-		}
-		`, GeneralApiInfoFuncName, GeneralApiInfoFuncName)
+	return pkgName, declaredIn, nil
 }
 
-func (m *Matte) LoadConfig() error {
-	/* funcSrc, err := m.GetConfigFuncSrc()
-	if err != nil {
-		return err
+// asks questions on out and reads the answers from in, every question is answered by its default when in is nil
+type questions struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+func (q *questions) ask(question, defaultAnswer string) string {
+	if q.in == nil {
+		return defaultAnswer
+	}
+	if q.out != nil {
+		fmt.Fprintf(q.out, "%v [%v]: ", question, defaultAnswer)
+	}
+	if !q.in.Scan() {
+		return defaultAnswer
+	}
+	answer := strings.TrimSpace(q.in.Text())
+	if answer == "" {
+		return defaultAnswer
 	}
-	modCache := os.Getenv("GOMODCACHE")
-	if modCache == "" {
-		return fmt.Errorf("only go modules enabled projects are supported")
+	return answer
+}
+
+func (q *questions) confirm(question string, defaultAnswer bool) bool {
+	choices := "y/N"
+	if defaultAnswer {
+		choices = "Y/n"
 	}
-	i := interp.New(interp.Options{GoPath: modCache})
-	i.Use(std_lib.Symbol) */
+	for {
+		switch strings.ToLower(q.ask(question+"?", choices)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case strings.ToLower(choices):
+			return defaultAnswer
+		}
+		if q.out != nil {
+			fmt.Fprintln(q.out, "answer y or n")
+		}
+	}
+}
 
-	return nil
+func (m *Matte) StringifyTheAstNode(node ast.Node) (string, error) {
+	strBuilder := strings.Builder{}
+	err := format.Node(&strBuilder, m.fileSet, node)
+	if err != nil {
+		return "", err
+	}
+	return strBuilder.String(), nil
 }
@@ -0,0 +1,72 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": usersPkg,
+	})
+	configPath := filepath.Join(dir, matte.ConfigFile)
+	err := matte.Configure(dir, matte.ConfigureOptions{})
+	if !assert.NoError(err) {
+		return
+	}
+	src, _ := os.ReadFile(configPath)
+	assert.Contains(string(src), "package main")
+	assert.Contains(string(src), "// @title   test\n// @version 0.0.0\nfunc MatteApp() config.Config {")
+	assert.Contains(string(src), `c.Addr = ":8000"`)
+	assert.Contains(string(src), "c.Outputs.TypeScriptClient = true")
+
+	err = matte.Configure(dir, matte.ConfigureOptions{Title: "users api"})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "already exists, use force to overwrite it")
+	}
+	src2, _ := os.ReadFile(configPath)
+	assert.Equal(src, src2)
+
+	var out strings.Builder
+	err = matte.Configure(dir, matte.ConfigureOptions{
+		Force: true,
+		Title: "users api",
+		In:    strings.NewReader("\n:9000\nn\nmaybe\nyes\n"),
+		Out:   &out,
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("version of the api [0.0.0]: address the app listens on [:8000]: "+
+		"serve the openapi document and the api explorer? [Y/n]: generate a go client? [Y/n]: answer y or n\n"+
+		"generate a go client? [Y/n]: generate a typescript client? [Y/n]: ", out.String())
+	src, _ = os.ReadFile(configPath)
+	assert.Contains(string(src), "// @title   users api\n")
+	assert.Contains(string(src), `c.Addr = ":9000"`)
+	assert.Contains(string(src), "c.Docs.Enabled = false")
+	assert.Contains(string(src), "c.Outputs.GoClient = true")
+
+	err = matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join(dir, matte.MatteDir, matte.OpenAPIJSONFile))
+	if assert.NoError(err) {
+		assert.Equal("users api", doc.Info.Title)
+		assert.Equal("0.0.0", doc.Info.Version)
+	}
+
+	os.Rename(configPath, filepath.Join(dir, "app.go"))
+	err = matte.Configure(dir, matte.ConfigureOptions{})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "MatteApp is already declared in "+filepath.Join(dir, "app.go"))
+	}
+}
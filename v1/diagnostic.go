@@ -0,0 +1,142 @@
+package matte
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Diagnostic is a build error which points at the src causing it,
+// it prints as "file:line:col: msg" so editors can jump to it
+type Diagnostic struct {
+	// position of the src in the fileSet, resolved into Position by Matte
+	Pos      token.Pos
+	Position token.Position
+	Msg      string
+	// text of the comment causing the error, if any
+	Comment string
+	// tells how to fix the error
+	Hint string
+}
+
+func (d *Diagnostic) Error() string {
+	s := d.Msg
+	if d.Position.IsValid() {
+		s = d.Position.String() + ": " + s
+	}
+	if d.Comment != "" {
+		s += "\n\t" + strings.TrimSpace(d.Comment)
+	}
+	if d.Hint != "" {
+		s += "\n\thint: " + d.Hint
+	}
+	return s
+}
+
+// errorAt returns a Diagnostic for the src at pos
+func errorAt(pos token.Pos, comment, hint string, format string, args ...any) *Diagnostic {
+	return &Diagnostic{
+		Pos:     pos,
+		Msg:     fmt.Sprintf(format, args...),
+		Comment: comment,
+		Hint:    hint,
+	}
+}
+
+// wraps err as a Diagnostic for the src at pos, unless it is one already
+func diagnosticAt(pos token.Pos, comment, hint string, err error) *Diagnostic {
+	var d *Diagnostic
+	if errors.As(err, &d) {
+		return d
+	}
+	return errorAt(pos, comment, hint, "%v", err)
+}
+
+// adds err to the diagnostics of the build
+func (m *Matte) addError(err error) {
+	m.diagnostics.Add(m.resolve(err))
+}
+
+// resolves the position of a Diagnostic, or of every one of Diagnostics, using the fileSet of m
+func (m *Matte) resolve(err error) error {
+	var diagnostics Diagnostics
+	if errors.As(err, &diagnostics) {
+		for _, d := range diagnostics {
+			m.resolve(d)
+		}
+		return diagnostics
+	}
+	var d *Diagnostic
+	if !errors.As(err, &d) {
+		return err
+	}
+	if !d.Position.IsValid() && d.Pos.IsValid() && m.fileSet != nil {
+		d.Position = m.fileSet.Position(d.Pos)
+	}
+	return d
+}
+
+// Diagnostics collects every error of a build so all of them can be reported at once
+type Diagnostics []*Diagnostic
+
+// Add adds err to the list, a scanner.ErrorList or Diagnostics is added as its individual errors
+func (ds *Diagnostics) Add(err error) {
+	var errList scanner.ErrorList
+	var diagnostics Diagnostics
+	var d *Diagnostic
+	switch {
+	case err == nil:
+	case errors.As(err, &errList):
+		// errors after the first one of a line are mostly the noise caused by it
+		errList.RemoveMultiples()
+		for _, e := range errList {
+			*ds = append(*ds, &Diagnostic{Position: e.Pos, Msg: e.Msg})
+		}
+	case errors.As(err, &diagnostics):
+		*ds = append(*ds, diagnostics...)
+	case errors.As(err, &d):
+		*ds = append(*ds, d)
+	default:
+		*ds = append(*ds, &Diagnostic{Msg: err.Error()})
+	}
+}
+
+// Err returns the sorted Diagnostics as an error, nil if there are none
+func (ds Diagnostics) Err() error {
+	if len(ds) == 0 {
+		return nil
+	}
+	sort.SliceStable(ds, func(i, j int) bool {
+		a, b := ds[i].Position, ds[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return ds
+}
+
+func (ds Diagnostics) Error() string {
+	s := ""
+	for _, d := range ds {
+		s += d.Error() + "\n"
+	}
+	if len(ds) == 1 {
+		return s + "1 error"
+	}
+	return s + fmt.Sprintf("%v errors", len(ds))
+}
+
+func (ds Diagnostics) Unwrap() []error {
+	errs := make([]error, len(ds))
+	for i, d := range ds {
+		errs[i] = d
+	}
+	return errs
+}
@@ -0,0 +1,4 @@
+package matte
+
+// exported for the tests of matte_test
+var RenderScaffold = renderScaffold
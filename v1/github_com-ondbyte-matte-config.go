@@ -0,0 +1,23 @@
+// Code generated by 'yaegi extract github.com/ondbyte/matte/config'. DO NOT EDIT.
+
+package matte
+
+import (
+	"github.com/ondbyte/matte/config"
+	"go/constant"
+	"go/token"
+	"reflect"
+)
+
+func init() {
+	Symbols["github.com/ondbyte/matte/config/config"] = map[string]reflect.Value{
+		// function, constant and variable definitions
+		"Default":    reflect.ValueOf(config.Default),
+		"HTTPRouter": reflect.ValueOf(constant.MakeFromLiteral("\"httprouter\"", token.STRING, 0)),
+
+		// type definitions
+		"Config":  reflect.ValueOf((*config.Config)(nil)),
+		"Docs":    reflect.ValueOf((*config.Docs)(nil)),
+		"Outputs": reflect.ValueOf((*config.Outputs)(nil)),
+	}
+}
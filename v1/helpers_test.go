@@ -0,0 +1,137 @@
+package matte_test
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondbyte/matte/v1"
+)
+
+// a pkg having a single handler, for the tests which need a project but not its handlers
+const usersPkg = `
+package users
+
+// @path("GET","/users/:id")
+func Get(id string) string { return id }
+`
+
+// writes the files into a new project dir having a go.mod
+func newTestProject(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	files["go.mod"] = "module github.com/ondbyte/test\n\ngo 1.20\n"
+	writeFiles(t, dir, files)
+	return dir
+}
+
+// same as newTestProject, but the project requires this matte so its app can be compiled,
+// the test is skipped if there is no go toolchain
+func newCompilableTestProject(t *testing.T, files map[string]string) string {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain is not installed")
+	}
+	dir := newTestProject(t, files)
+	repo, _ := filepath.Abs("..")
+	goSum, _ := os.ReadFile(filepath.Join(repo, "go.sum"))
+	writeFiles(t, dir, map[string]string{
+		"go.sum": string(goSum),
+		"go.mod": `module github.com/ondbyte/test
+
+go 1.20
+
+require (
+	github.com/julienschmidt/httprouter v1.3.0
+	github.com/ondbyte/matte v0.0.0
+)
+
+replace github.com/ondbyte/matte => ` + repo + "\n",
+	})
+	return dir
+}
+
+// builds the app of a compilable project having the files and runs it, returns the url it listens on,
+// the app is stopped once the test is done
+func serveTestProject(t *testing.T, files map[string]string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	files[matte.ConfigTOMLFile] = fmt.Sprintf("addr = %q\n", addr)
+	dir := newCompilableTestProject(t, files)
+	output := filepath.Join(t.TempDir(), "app")
+	stderr := &strings.Builder{}
+	err = matte.BuildProject(token.NewFileSet(), dir, matte.BuildOptions{Output: output, Stderr: stderr})
+	if err != nil {
+		t.Fatalf("unable to build the app due to err: %v\n%v", err, stderr)
+	}
+	cmd := exec.Command(output)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(20 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return "http://" + addr
+		}
+	}
+	t.Fatalf("app does not listen on %v", addr)
+	return ""
+}
+
+// returns the status and body of the response to a GET of the url
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// writes the files into dir, their names are relative to it
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, src := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// returns the src of the app generated into the matte dir of the project
+func readApp(t *testing.T, dir string) string {
+	app, err := os.ReadFile(filepath.Join(dir, matte.MatteDir, matte.AppFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(app)
+}
+
+func keys[V any](m map[string]V) []string {
+	ks := []string{}
+	for k := range m {
+		ks = append(ks, k)
+	}
+	return ks
+}
@@ -0,0 +1,523 @@
+package matte
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// files written by ImportOpenAPI into the pkg dir
+	ImportedTypesFile    = "types.go"
+	ImportedHandlersFile = "handlers.go"
+)
+
+// ImportOpenAPI writes decorated handler stubs and the structs of their requests and responses for every
+// operation of the openapi document at specPath into pkgDir, files of pkgDir are overwritten only if force is true.
+// returns a warning for everything of the document which cannot be expressed by matte
+func ImportOpenAPI(specPath, pkgDir string, force bool) (warnings []string, err error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile(specPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load openapi document %v due to err: %v", specPath, err)
+	}
+	pkgDir, err = filepath.Abs(pkgDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve pkg dir due to err: %v", err)
+	}
+	if !force {
+		for _, file := range []string{filepath.Join(pkgDir, ImportedTypesFile), filepath.Join(pkgDir, ImportedHandlersFile)} {
+			if _, err := os.Stat(file); err == nil {
+				return nil, fmt.Errorf("%v already exists, pass force to overwrite it", file)
+			}
+		}
+	}
+
+	g := &stubGen{
+		pkgName: strings.ToLower(goIdent(filepath.Base(pkgDir), false)),
+		types:   map[string]string{},
+		names:   map[string]bool{},
+		refs:    map[string]string{},
+	}
+	if doc.Components != nil {
+		// the schemas are named before any of them is declared, so a ref resolves to its schema even if its name collides
+		schemas := sortedKeys(doc.Components.Schemas)
+		for _, name := range schemas {
+			g.refs[name] = g.uniqueName(goIdent(name, true))
+		}
+		for _, name := range schemas {
+			g.declareType(g.refs[name], doc.Components.Schemas[name].Value)
+		}
+	}
+	handlers := &strings.Builder{}
+	for _, path := range sortedKeys(doc.Paths.Map()) {
+		pathItem := doc.Paths.Value(path)
+		operations := pathItem.Operations()
+		for _, method := range sortedKeys(operations) {
+			handlers.WriteString(g.handler(method, path, pathItem.Parameters, operations[method]))
+		}
+	}
+
+	types := g.typesSrc()
+	typesSrc := fmt.Sprintf(`// types of the requests and responses of the api, imported from %v
+package %v
+
+%v
+%v
+`, filepath.Base(specPath), g.pkgName, importsSrc(types), types)
+	handlersSrc := fmt.Sprintf(`// handlers of the api, imported from %v
+package %v
+
+%v
+%v
+`, filepath.Base(specPath), g.pkgName, importsSrc(handlers.String(), WebImportPath), handlers)
+
+	err = os.MkdirAll(pkgDir, 0777)
+	if err != nil {
+		return nil, fmt.Errorf("unable to mkdir %v due to err: %v", pkgDir, err)
+	}
+	for file, src := range map[string]string{
+		filepath.Join(pkgDir, ImportedTypesFile):    typesSrc,
+		filepath.Join(pkgDir, ImportedHandlersFile): handlersSrc,
+	} {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return nil, fmt.Errorf("failed to format the src of %v due to err: %v", file, err)
+		}
+		err = os.WriteFile(file, formatted, 0666)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write file %v due to err: %v", file, err)
+		}
+	}
+	return g.warnings, nil
+}
+
+// generates the go src of the handler stubs and their types
+type stubGen struct {
+	pkgName string
+	// src of each named type by its name
+	types map[string]string
+	// every identifier declared in the pkg
+	names map[string]bool
+	// declared name of each schema of the components by its name in the document
+	refs     map[string]string
+	warnings []string
+}
+
+func (g *stubGen) warnf(format string, args ...any) {
+	g.warnings = append(g.warnings, fmt.Sprintf(format, args...))
+}
+
+// returns name, or name suffixed with a number if it is already declared in the pkg
+func (g *stubGen) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// returns the import decl of the src, time is imported only if the src uses it
+func importsSrc(src string, imports ...string) string {
+	if strings.Contains(src, "time.Time") {
+		imports = append([]string{"time"}, imports...)
+	}
+	if len(imports) == 0 {
+		return ""
+	}
+	decl := "import (\n"
+	for _, i := range imports {
+		decl += strconv.Quote(i) + "\n"
+	}
+	return decl + ")\n"
+}
+
+func (g *stubGen) typesSrc() string {
+	src := ""
+	for _, name := range sortedKeys(g.types) {
+		src += g.types[name] + "\n"
+	}
+	return src
+}
+
+// declares the named type having the schema and returns its name
+func (g *stubGen) namedType(name string, schema *openapi3.Schema) string {
+	return g.declareType(g.uniqueName(name), schema)
+}
+
+// declares the type having the schema by the name, which must be unique, and returns it
+func (g *stubGen) declareType(name string, schema *openapi3.Schema) string {
+	doc := ""
+	if schema.Description != "" {
+		doc = "// " + name + " " + strings.ReplaceAll(schema.Description, "\n", "\n// ") + "\n"
+	}
+	if schema.Type == openapi3.TypeObject && schema.AdditionalProperties.Schema == nil || len(schema.AllOf) > 0 {
+		g.types[name] = fmt.Sprintf("%vtype %v struct {\n%v}\n", doc, name, g.fieldsSrc(name, schema))
+		return name
+	}
+	g.types[name] = fmt.Sprintf("%vtype %v %v\n", doc, name, g.typeOf(name, nil, schema, true))
+	return name
+}
+
+// returns the fields of the struct having the schema, allOf refs are embedded
+func (g *stubGen) fieldsSrc(structName string, schema *openapi3.Schema) string {
+	src := ""
+	for _, part := range schema.AllOf {
+		if part.Ref != "" {
+			src += g.refName(part.Ref) + "\n"
+			continue
+		}
+		src += g.fieldsSrc(structName, part.Value)
+	}
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+		required := contains(schema.Required, name)
+		fieldName := goIdent(name, true)
+		fieldType := g.typeOf(structName+fieldName, property, property.Value, required)
+		tag := name
+		if !required {
+			tag += ",omitempty"
+		}
+		if property.Value.Description != "" {
+			src += "// " + fieldName + " " + strings.ReplaceAll(property.Value.Description, "\n", "\n// ") + "\n"
+		}
+		src += fmt.Sprintf("%v %v `json:%q`\n", fieldName, fieldType, tag)
+	}
+	return src
+}
+
+// returns the go type of the schema, named is the name of the type declared for an inline object,
+// optional scalars and structs are pointers
+func (g *stubGen) typeOf(name string, ref *openapi3.SchemaRef, schema *openapi3.Schema, required bool) string {
+	pointer := ""
+	if !required {
+		pointer = "*"
+	}
+	if ref != nil && ref.Ref != "" {
+		return pointer + g.refName(ref.Ref)
+	}
+	if schema == nil {
+		return "any"
+	}
+	switch schema.Type {
+	case openapi3.TypeString:
+		switch schema.Format {
+		case "date-time":
+			return pointer + "time.Time"
+		case "byte", "binary":
+			return "[]byte"
+		}
+		return pointer + "string"
+	case openapi3.TypeInteger:
+		switch schema.Format {
+		case "int32":
+			return pointer + "int32"
+		case "int64":
+			return pointer + "int64"
+		}
+		return pointer + "int"
+	case openapi3.TypeNumber:
+		if schema.Format == "float" {
+			return pointer + "float32"
+		}
+		return pointer + "float64"
+	case openapi3.TypeBoolean:
+		return pointer + "bool"
+	case openapi3.TypeArray:
+		if schema.Items == nil {
+			return "[]any"
+		}
+		return "[]" + g.typeOf(name+"Item", schema.Items, schema.Items.Value, true)
+	case openapi3.TypeObject, "":
+		if schema.AdditionalProperties.Schema != nil {
+			additional := schema.AdditionalProperties.Schema
+			return "map[string]" + g.typeOf(name+"Value", additional, additional.Value, true)
+		}
+		if len(schema.Properties) > 0 || len(schema.AllOf) > 0 {
+			return pointer + g.namedType(name, schema)
+		}
+		if schema.Type == openapi3.TypeObject {
+			return "map[string]any"
+		}
+	}
+	return "any"
+}
+
+// returns the src of the decorated handler stub of the operation
+func (g *stubGen) handler(method, path string, pathParams openapi3.Parameters, op *openapi3.Operation) string {
+	name := goIdent(op.OperationID, true)
+	if op.OperationID == "" {
+		name = goIdent(strings.ToLower(method)+" "+strings.NewReplacer("{", " by ", "}", " ").Replace(path), true)
+	}
+	name = g.uniqueName(name)
+	where := method + " " + path
+
+	// go params of the handler, queries and body are the names of the query and body params
+	params, queries, body := []string{}, []string{}, ""
+	parameters := append(openapi3.Parameters{}, pathParams...)
+	for _, p := range op.Parameters {
+		// params of the operation override the ones of the path
+		parameters = append(parameters, p)
+	}
+	// names of the go params of the path params which are not go identifiers, by the name of their wildcard
+	wildcards := map[string]string{}
+	seen := map[string]bool{}
+	for i := len(parameters) - 1; i >= 0; i-- {
+		p := parameters[i].Value
+		if seen[p.In+p.Name] {
+			parameters = append(parameters[:i], parameters[i+1:]...)
+			continue
+		}
+		seen[p.In+p.Name] = true
+	}
+	for _, parameter := range parameters {
+		p := parameter.Value
+		if p.In != openapi3.ParameterInPath && p.In != openapi3.ParameterInQuery {
+			g.warnf("%v: %v param '%v' is left out, read it from the request instead", where, p.In, p.Name)
+			continue
+		}
+		paramName := p.Name
+		if !token.IsIdentifier(p.Name) {
+			if p.In == openapi3.ParameterInQuery {
+				g.warnf("%v: %v param '%v' is left out as it is not a valid go identifier", where, p.In, p.Name)
+				continue
+			}
+			// a wildcard can be renamed along with its param, as its name is not a part of the request
+			paramName = goIdent(p.Name, false)
+			wildcards[p.Name] = paramName
+			g.warnf("%v: %v param '%v' is renamed to %v as it is not a valid go identifier", where, p.In, p.Name, paramName)
+		}
+		typ := "string"
+		if p.Schema != nil {
+			typ = g.typeOf(name+goIdent(p.Name, true), p.Schema, p.Schema.Value, true)
+		}
+		if !isScalarGoType(typ) {
+			g.warnf("%v: %v param '%v' of type %v is read as a string, as only scalars can be read from the %v", where, p.In, p.Name, typ, p.In)
+			typ = "string"
+		}
+		if !p.Required && p.In == openapi3.ParameterInQuery {
+			typ = "*" + typ
+		}
+		params = append(params, paramName+" "+typ)
+		if p.In == openapi3.ParameterInQuery {
+			queries = append(queries, strconv.Quote(p.Name))
+		}
+	}
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		requestBody := op.RequestBody.Value
+		mediaType := requestBody.Content.Get(ContentTypeJSON)
+		if mediaType == nil || mediaType.Schema == nil {
+			g.warnf("%v: request body is left out as it is not %v, read it from the request instead", where, ContentTypeJSON)
+		} else {
+			typ := g.typeOf(name+"Request", mediaType.Schema, mediaType.Schema.Value, requestBody.Required)
+			body = "body"
+			if mediaType.Schema.Ref != "" {
+				body = goIdent(g.refName(mediaType.Schema.Ref), false)
+			}
+			if seen[openapi3.ParameterInPath+body] || seen[openapi3.ParameterInQuery+body] {
+				body += "Body"
+			}
+			params = append(params, body+" "+typ)
+		}
+	}
+
+	// results of the handler
+	status, contentType, result := g.response(name, where, op)
+	results, returns := "error", "web.ErrNotImplemented"
+	if result != "" {
+		// the name of the result tells matte its content type
+		resultName := ""
+		switch contentType {
+		case ContentTypeText:
+			resultName = "textResponse "
+		case ContentTypeHTML:
+			resultName = "htmlResponse "
+		}
+		results = fmt.Sprintf("(%v%v, err error)", resultName, result)
+		if resultName == "" {
+			results = fmt.Sprintf("(%v, error)", result)
+		}
+		returns = zeroValue(result) + ", " + returns
+	}
+
+	routerPath := strings.Split(toRouterPath(path), "/")
+	for i, segment := range routerPath {
+		if name, ok := wildcards[strings.TrimPrefix(segment, ":")]; ok && strings.HasPrefix(segment, ":") {
+			routerPath[i] = ":" + name
+		}
+	}
+	decorators := []string{fmt.Sprintf("@path(%q,%q)", method, strings.Join(routerPath, "/"))}
+	if len(queries) > 0 {
+		decorators = append(decorators, fmt.Sprintf("@query(%v)", strings.Join(queries, ",")))
+	}
+	if body != "" {
+		decorators = append(decorators, fmt.Sprintf("@body(%q)", body))
+	}
+	if result != "" && contentType != ContentTypeJSON && contentType != ContentTypeText && contentType != ContentTypeHTML {
+		decorators = append(decorators, fmt.Sprintf("@produces(%q)", contentType))
+	}
+	if (result != "" && status != http.StatusOK) || (result == "" && status != http.StatusNoContent) {
+		decorators = append(decorators, fmt.Sprintf("@status(%v)", status))
+	}
+	if op.Summary != "" {
+		decorators = append(decorators, fmt.Sprintf("@summary(%q)", op.Summary))
+	}
+	if len(op.Tags) > 0 {
+		tags := []string{}
+		for _, tag := range op.Tags {
+			tags = append(tags, strconv.Quote(tag))
+		}
+		decorators = append(decorators, fmt.Sprintf("@tags(%v)", strings.Join(tags, ",")))
+	}
+	if op.Deprecated {
+		decorators = append(decorators, "@deprecated()")
+	}
+	if op.Security != nil && len(*op.Security) > 0 {
+		g.warnf("%v: security is left out, add a @security decorator once MatteApp defines the security schemes", where)
+	}
+
+	doc := "// " + name + " handles " + where + "\n"
+	if op.Description != "" {
+		doc += "//\n// " + strings.ReplaceAll(strings.TrimSpace(op.Description), "\n", "\n// ") + "\n"
+	}
+	for _, decorator := range decorators {
+		doc += "// " + decorator + "\n"
+	}
+	return fmt.Sprintf("%vfunc %v(%v) %v {\n\treturn %v\n}\n\n", doc, name, strings.Join(params, ", "), results, returns)
+}
+
+// returns the status, content type and go type of the success response of the operation,
+// the go type is empty if the response has no content
+func (g *stubGen) response(name, where string, op *openapi3.Operation) (status int, contentType, typ string) {
+	codes := []int{}
+	for code := range op.Responses.Map() {
+		if c, err := strconv.Atoi(code); err == nil && c >= 200 && c < 300 {
+			codes = append(codes, c)
+		}
+	}
+	if len(codes) == 0 {
+		return http.StatusNoContent, "", ""
+	}
+	sort.Ints(codes)
+	status = codes[0]
+	response := op.Responses.Status(status).Value
+	if len(response.Content) == 0 {
+		return status, "", ""
+	}
+	contentTypes := sortedKeys(response.Content)
+	contentType = contentTypes[0]
+	if response.Content.Get(ContentTypeJSON) != nil {
+		contentType = ContentTypeJSON
+	}
+	mediaType := response.Content.Get(contentType)
+	switch {
+	case contentType == ContentTypeJSON && mediaType.Schema != nil:
+		typ = g.typeOf(name+"Response", mediaType.Schema, mediaType.Schema.Value, false)
+		if strings.HasPrefix(typ, "*") && isScalarGoType(typ[1:]) {
+			typ = typ[1:]
+		}
+	case strings.HasPrefix(contentType, "text/"):
+		typ = "string"
+	default:
+		typ = "[]byte"
+	}
+	if len(contentTypes) > 1 {
+		g.warnf("%v: only the %v response is generated", where, contentType)
+	}
+	return status, contentType, typ
+}
+
+// returns the name of the go type declared for the schema of the ref
+func (g *stubGen) refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if declared, ok := g.refs[name]; ok {
+		return declared
+	}
+	return goIdent(name, true)
+}
+
+// returns the zero value of the go type as a go expression
+func zeroValue(typ string) string {
+	switch {
+	case strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["), typ == "any":
+		return "nil"
+	case typ == "string":
+		return `""`
+	case typ == "bool":
+		return "false"
+	case isScalarGoType(typ):
+		return "0"
+	}
+	return typ + "{}"
+}
+
+func isScalarGoType(typ string) bool {
+	switch typ {
+	case "string", "bool", "int", "int32", "int64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+// initialisms kept upper cased in go identifiers, ex: userId becomes UserID
+var initialisms = map[string]bool{"ID": true, "URL": true, "URI": true, "HTTP": true, "API": true, "JSON": true, "UUID": true}
+
+// converts s to a go identifier, ex: "user-id" becomes UserID if exported, else userID
+func goIdent(s string, exported bool) string {
+	words := []string{}
+	word := []rune{}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			words, word = append(words, string(word)), nil
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			words, word = append(words, string(word)), []rune{r}
+		default:
+			word = append(word, r)
+		}
+	}
+	words = append(words, string(word))
+	ident := ""
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if upper := strings.ToUpper(w); initialisms[upper] && (ident != "" || exported) {
+			ident += upper
+			continue
+		}
+		if ident == "" && !exported {
+			ident += strings.ToLower(w[:1]) + w[1:]
+			continue
+		}
+		ident += strings.ToUpper(w[:1]) + w[1:]
+	}
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	if token.IsKeyword(ident) {
+		ident += "_"
+	}
+	return ident
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,238 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestImportOpenAPI(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"spec.yaml": `
+openapi: 3.0.3
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: lists the pets
+      tags: [pets]
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, format: int32}}
+        - {name: X-Request-Id, in: header, schema: {type: string}}
+      responses:
+        "200":
+          description: the pets
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: "#/components/schemas/Pet"}}
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/NewPet"}
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+  /pets/{petId}:
+    delete:
+      parameters:
+        - {name: petId, in: path, required: true, schema: {type: integer, format: int64}}
+      responses:
+        "204": {description: deleted}
+components:
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        tag: {type: string}
+        bornAt: {type: string, format: date-time}
+    Pet:
+      allOf:
+        - $ref: "#/components/schemas/NewPet"
+        - type: object
+          required: [id]
+          properties:
+            id: {type: integer, format: int64}
+            owner:
+              type: object
+              properties:
+                name: {type: string}
+`})
+	warnings, err := matte.ImportOpenAPI(filepath.Join(dir, "spec.yaml"), filepath.Join(dir, "handlers"), false)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]string{"GET /pets: header param 'X-Request-Id' is left out, read it from the request instead"}, warnings)
+	handlers, err := os.ReadFile(filepath.Join(dir, "handlers", matte.ImportedHandlersFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(string(handlers), `// @path("GET","/pets")
+// @query("limit")
+// @summary("lists the pets")
+// @tags("pets")
+func ListPets(limit *int32) ([]Pet, error) {`)
+	assert.Contains(string(handlers), `// @path("POST","/pets")
+// @body("newPet")
+// @status(201)
+func CreatePet(newPet NewPet) (*Pet, error) {`)
+	assert.Contains(string(handlers), `// @path("DELETE","/pets/:petId")
+func DeletePetsByPetID(petId int64) error {`)
+	types, err := os.ReadFile(filepath.Join(dir, "handlers", matte.ImportedTypesFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(string(types), "BornAt *time.Time `json:\"bornAt,omitempty\"`")
+	assert.Contains(string(types), "Owner *PetOwner `json:\"owner,omitempty\"`")
+
+	_, err = matte.ImportOpenAPI(filepath.Join(dir, "spec.yaml"), filepath.Join(dir, "handlers"), false)
+	assert.Error(err, "stubs must not be overwritten without force")
+
+	// the stubs are valid handlers of matte
+	err = matte.Build(token.NewFileSet(), dir)
+	assert.NoError(err)
+}
+
+func TestImportOpenAPIWithoutComponents(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"spec.yaml": `
+openapi: 3.0.3
+info:
+  title: health
+  version: 1.0.0
+paths:
+  /health:
+    get:
+      operationId: health
+      responses:
+        "204": {description: healthy}
+`})
+	_, err := matte.ImportOpenAPI(filepath.Join(dir, "spec.yaml"), filepath.Join(dir, "handlers"), false)
+	if !assert.NoError(err) {
+		return
+	}
+	handlers, err := os.ReadFile(filepath.Join(dir, "handlers", matte.ImportedHandlersFile))
+	if assert.NoError(err) {
+		assert.Contains(string(handlers), `// @path("GET","/health")
+func Health() error {`)
+	}
+}
+
+func TestImportOpenAPIRefsOfCollidingSchemas(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"spec.yaml": `
+openapi: 3.0.3
+info:
+  title: users
+  version: 1.0.0
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/user"}
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: integer}
+        address:
+          type: object
+          properties:
+            city: {type: string}
+        home: {$ref: "#/components/schemas/UserAddress"}
+    user:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+    UserAddress:
+      type: object
+      properties:
+        street: {type: string}
+`})
+	_, err := matte.ImportOpenAPI(filepath.Join(dir, "spec.yaml"), filepath.Join(dir, "handlers"), false)
+	if !assert.NoError(err) {
+		return
+	}
+	handlers, err := os.ReadFile(filepath.Join(dir, "handlers", matte.ImportedHandlersFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(string(handlers), "func CreateUser(user2 User2) (*User, error) {")
+	types, err := os.ReadFile(filepath.Join(dir, "handlers", matte.ImportedTypesFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(string(types), "type User2 struct {\n\tName string `json:\"name\"`")
+	assert.Contains(string(types), "type UserAddress struct {\n\tStreet *string")
+	assert.Contains(string(types), "Address *UserAddress2 `json:\"address,omitempty\"`")
+	assert.Contains(string(types), "Home    *UserAddress  `json:\"home,omitempty\"`")
+
+	err = matte.Build(token.NewFileSet(), dir)
+	assert.NoError(err)
+}
+
+func TestImportOpenAPIPathParamsWhichAreNotGoIdentifiers(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"spec.yaml": `
+openapi: 3.0.3
+info:
+  title: items
+  version: 1.0.0
+paths:
+  /items/{type}/{item-id}:
+    get:
+      parameters:
+        - {name: type, in: path, required: true, schema: {type: string}}
+        - {name: item-id, in: path, required: true, schema: {type: string}}
+        - {name: func, in: query, schema: {type: string}}
+      responses:
+        "204": {description: found}
+`})
+	warnings, err := matte.ImportOpenAPI(filepath.Join(dir, "spec.yaml"), filepath.Join(dir, "ItemHandlers"), false)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]string{
+		"GET /items/{type}/{item-id}: path param 'type' is renamed to type_ as it is not a valid go identifier",
+		"GET /items/{type}/{item-id}: path param 'item-id' is renamed to itemID as it is not a valid go identifier",
+		"GET /items/{type}/{item-id}: query param 'func' is left out as it is not a valid go identifier",
+	}, warnings)
+	handlers, err := os.ReadFile(filepath.Join(dir, "ItemHandlers", matte.ImportedHandlersFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(string(handlers), "package itemhandlers")
+	assert.Contains(string(handlers), `// @path("GET","/items/:type_/:itemID")
+func GetItemsByTypeByItemID(type_ string, itemID string) error {`)
+
+	// the renamed params are the wildcards of the path
+	err = matte.Build(token.NewFileSet(), dir)
+	assert.NoError(err)
+}
@@ -0,0 +1,151 @@
+package matte
+
+import (
+	"go/ast"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pkgs the generated src of the app and the scaffold refer to by their names
+var appImports = map[string]string{
+	"context":    "context",
+	"embed":      "embed",
+	"errors":     "errors",
+	"fmt":        "fmt",
+	"http":       "net/http",
+	"httprouter": "github.com/julienschmidt/httprouter",
+	"io":         "io",
+	"json":       "encoding/json",
+	"log":        "log",
+	"mime":       "mime",
+	"os":         "os",
+	"signal":     "os/signal",
+	"syscall":    "syscall",
+	"time":       "time",
+	"web":        WebImportPath,
+}
+
+// names declared by the scaffold, and by the src of the handlers which refers to the pkgs of their types
+var appDeclared = []string{
+	"DefaultPort", "ServerRunners", "ServerRunner", "ShutDowner", "RunHTTPServer", "main", "openAPISpec",
+	"router", "banner", "version", "addr", "server", "errChan",
+	"w", "r", "p", "err", "invalidParams", "queryValues", "mediaType", "maxBytesErr", "handlerResult", "resultBytes",
+}
+
+// imports of a generated src, every pkg is referred to by a name which neither another pkg nor a declaration
+// of the src has, so pkgs having the same name, or imported using an alias, can be used together
+type importSet struct {
+	// import paths in the order they are required, along with the name of each
+	paths []string
+	names map[string]string
+	// pkgs the src refers to by a fixed name, by the name
+	fixed map[string]string
+	// names declared by the src, no pkg is imported by one of them
+	declared map[string]bool
+}
+
+func newImportSet(fixed map[string]string, declared ...string) *importSet {
+	s := &importSet{names: map[string]string{}, fixed: fixed, declared: map[string]bool{}}
+	for _, name := range declared {
+		s.declared[name] = true
+	}
+	return s
+}
+
+// adds the import, only once, and returns the name the src refers to its pkg by, which is name unless it is taken,
+// then it is suffixed with a number, a pkg the src refers to by a fixed name is always imported by it
+func (s *importSet) add(importPath, name string) string {
+	if existing, ok := s.names[importPath]; ok {
+		return existing
+	}
+	for fixedName, fixedPath := range s.fixed {
+		if fixedPath == importPath {
+			name = fixedName
+		}
+	}
+	unique := name
+	for i := 2; s.fixed[unique] != "" && s.fixed[unique] != importPath || s.declared[unique] || s.isImportedAs(unique); i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	s.paths = append(s.paths, importPath)
+	s.names[importPath] = unique
+	return unique
+}
+
+func (s *importSet) isImportedAs(name string) bool {
+	for _, n := range s.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// returns a copy of the set, to add imports which are dropped unless they are all used
+func (s *importSet) clone() *importSet {
+	c := &importSet{paths: append([]string{}, s.paths...), names: map[string]string{}, fixed: s.fixed, declared: s.declared}
+	for importPath, name := range s.names {
+		c.names[importPath] = name
+	}
+	return c
+}
+
+// returns the import specs, ex: "fmt" or yaml "gopkg.in/yaml.v3", an import is named unless its name is the last
+// element of its path
+func (s *importSet) specs() []string {
+	specs := []string{}
+	for _, importPath := range s.paths {
+		specs = append(specs, s.spec(importPath))
+	}
+	return specs
+}
+
+func (s *importSet) spec(importPath string) string {
+	if name := s.names[importPath]; name != path.Base(importPath) {
+		return name + " " + strconv.Quote(importPath)
+	}
+	return strconv.Quote(importPath)
+}
+
+// returns the name a pkg is assumed to have when it is imported without one, same as goimports does,
+// ex: yaml for gopkg.in/yaml.v3, chi for github.com/go-chi/chi/v5
+func assumedPkgName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil && path.Dir(importPath) != "." {
+			base = path.Base(path.Dir(importPath))
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+// returns the import path of the pkg the file refers to by the name
+func (m *Matte) importPathOf(file *ast.File, name string) (string, bool) {
+	for _, i := range file.Imports {
+		importPath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		importName := assumedPkgName(importPath)
+		for _, pkg := range m.Pkgs {
+			if pkg.ImportPath == importPath {
+				importName = pkg.Name
+			}
+		}
+		if i.Name != nil {
+			importName = i.Name.Name
+		}
+		if importName == name {
+			return importPath, true
+		}
+	}
+	return "", false
+}
@@ -3,13 +3,20 @@ package matte
 import (
 	"fmt"
 	"go/ast"
-	"go/format"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/go-openapi/spec"
+	"github.com/ondbyte/matte/config"
+	"github.com/ondbyte/matte/scaffold"
+	"github.com/ondbyte/matte/web"
 	"github.com/rogpeppe/go-internal/modfile"
 )
 
@@ -26,19 +33,40 @@ type Matte struct {
 	corePkg  *Pkg
 	// refers the pkg which is being processed
 	currentPkg *Pkg
-	Pkgs       []*Pkg
-	modFile    *modfile.File
-	src        string
-	imports    string
+	// refers the file which is being processed
+	currentFile *ast.File
+	Pkgs        []*Pkg
+	// every route found in the project
+	Routes  []*Route
+	modFile *modfile.File
+	src     string
+	// imports required by the generated src
+	imports *importSet
+	// errors found while processing the project
+	diagnostics Diagnostics
+	// general api info read from the swag annotations of the MatteApp func
+	apiInfo *spec.Swagger
+	config  config.Config
+	// file the config is read from, empty if the defaults are used
+	configSource string
+	// value of MATTE_ENV the project is built for, selects the env overrides of matte.toml
+	env string
 }
 
+// default output dir, where the app is generated into
 const MatteDir = "matte"
 
-func createMatteDir(projectDir string) (string, error) {
-	projectDir = filepath.Clean(projectDir)
-	dir := filepath.Join(projectDir, MatteDir)
+// file of the generated app inside the matte dir
+const AppFile = "app.go"
+
+// makes an empty output dir, a dir having files but not an app is refused as it is not generated by matte
+func createMatteDir(dir string) (string, error) {
 	stat, err := os.Stat(dir)
 	if stat != nil && err == nil {
+		entries, _ := os.ReadDir(dir)
+		if _, appErr := os.Stat(filepath.Join(dir, AppFile)); len(entries) > 0 && appErr != nil {
+			return "", fmt.Errorf("%v is not generated by matte as it has no %v, so it is not deleted, change the output dir", dir, AppFile)
+		}
 		err := os.RemoveAll(dir)
 		if err != nil {
 			return "", fmt.Errorf("unable to delete matte dir due to err: %v", err)
@@ -47,66 +75,164 @@ func createMatteDir(projectDir string) (string, error) {
 	if err != nil && !os.IsNotExist(err) {
 		return "", fmt.Errorf("error while checking if the matteDir already exists due to err: %v", err)
 	}
-	err = os.Mkdir(dir, 0777)
+	err = os.MkdirAll(dir, 0777)
 	if err != nil {
 		return "", fmt.Errorf("unable to mkdir %v due to err: %v", dir, err)
 	}
 	return dir, nil
 }
 
-// builds the project at path 'project'
+// generates the app of the project at path 'project' into its matte dir, BuildProject compiles it too
 func Build(fileSet *token.FileSet, project string) error {
-	matteDir, err := createMatteDir(project)
+	_, err := generate(fileSet, project, web.Env())
+	return err
+}
+
+// generates the app of the project for the env
+func generate(fileSet *token.FileSet, project, env string) (*Matte, error) {
+	m, err := load(fileSet, project, env)
 	if err != nil {
-		return fmt.Errorf("unable to make matteDir due to err: %v", err)
+		return nil, err
 	}
-	m := &Matte{
-		fileSet:  fileSet,
-		wd:       project,
-		matteDir: matteDir,
+	_, err = createMatteDir(m.matteDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to make matteDir due to err: %v", err)
 	}
 	// defer clean up
 	//defer m.DeferCleanUp()
-	err = m.parseModFile()
+	err = m.build()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if m.config.Outputs.OpenAPI {
+		err = m.writeOpenAPI()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.config.Outputs.GoClient {
+		err = m.writeClient()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if m.config.Outputs.TypeScriptClient {
+		err = m.writeTypeScriptClient()
+		if err != nil {
+			return nil, err
+		}
 	}
+	return m, nil
+}
+
+// Load parses and processes the project at path 'project' without writing anything,
+// every route of the project is in Routes of the returned Matte
+// the config is read for the env the app runs in, see web.Env
+func Load(fileSet *token.FileSet, project string) (*Matte, error) {
+	return load(fileSet, project, web.Env())
+}
+
+func load(fileSet *token.FileSet, project, env string) (*Matte, error) {
+	m, err := loadConfigured(fileSet, project, env)
+	if err != nil {
+		return nil, err
+	}
+	m.addError(m.ParseGeneralAPIInfo())
+	m.processProject()
+	m.checkSecurity()
+	m.checkBuiltinPaths()
+	err = m.diagnostics.Err()
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parses the project and loads its config for the env, without processing the handlers
+func loadConfigured(fileSet *token.FileSet, project, env string) (*Matte, error) {
+	m := &Matte{
+		fileSet: fileSet,
+		wd:      project,
+		config:  config.Default(),
+		env:     env,
+		imports: newImportSet(appImports, appDeclared...),
+	}
+	err := m.parseModFile()
+	if err != nil {
+		return nil, err
+	}
+	// matte.toml decides the dirs which are parsed, so it is loaded before the project
+	err = m.loadTOMLConfig()
+	if err != nil {
+		return nil, err
+	}
+	m.matteDir = filepath.Join(project, m.config.OutputDir)
 	err = m.loadProject()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = m.processProject()
+	err = m.LoadConfig()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = m.build()
-	return err
+	// a MatteApp func may change the dirs which are parsed
+	m.matteDir = filepath.Join(project, m.config.OutputDir)
+	pkgs := []*Pkg{}
+	for _, pkg := range m.Pkgs {
+		if pkg == m.corePkg || !m.isSkippedDir(m.pkgDir(pkg)) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	m.Pkgs = pkgs
+	return m, nil
+}
+
+// whether the dir is the output dir, or an excluded dir, or inside one
+func (m *Matte) isSkippedDir(dir string) bool {
+	for _, skipped := range append([]string{m.config.OutputDir}, m.config.Exclude...) {
+		rel, err := filepath.Rel(filepath.Join(m.wd, skipped), dir)
+		if err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the dir of the pkg
+func (m *Matte) pkgDir(pkg *Pkg) string {
+	for fileName := range pkg.Files {
+		return filepath.Dir(fileName)
+	}
+	return filepath.Join(m.wd, strings.TrimPrefix(strings.TrimPrefix(pkg.ImportPath, m.modFile.Module.Mod.Path), "/"))
 }
 
 func (m *Matte) build() error {
-	srcS := fmt.Sprintf(`
-	package main
-
-	import (
-		"encoding/json"
-		"fmt"
-		"net/http"
-	
-		"github.com/julienschmidt/httprouter"
-		%v
-	)
-	func main(){
-		router := httprouter.New()
-		%v
-	}
-	`, m.imports, m.src)
-	_, err := format.Source([]byte(srcS))
+	// imports are required while generating the src, so the imports must be the last ones
+	docsSrc, versionSrc := m.docsSrc(), m.versionSrc()
+	imports := []string{}
+	// the openapi document is embedded only for the docs to serve it
+	embedSrc := ""
+	if m.config.Docs.Enabled {
+		embedSrc = fmt.Sprintf("//go:embed %v\nvar openAPISpec []byte", OpenAPIJSONFile)
+		imports = append(imports, `_ "embed"`)
+	}
+	imports = append(imports, m.imports.specs()...)
+	// the app listens on DefaultPort of the scaffold, the default addr of the config, unless the config sets another
+	portSrc := ""
+	if m.config.Addr != config.Default().Addr {
+		portSrc = fmt.Sprintf("addr = %q", m.config.Addr)
+	}
+	src, err := renderScaffold(scaffold.Files, imports, map[string]string{
+		"declsPlaceHolder":    embedSrc,
+		"handlersPlaceHolder": m.src + "\n" + docsSrc + "\n" + versionSrc + "\nbanner = version.String()",
+		"portPlaceHolder":     portSrc,
+	})
 	if err != nil {
-		//return fmt.Errorf("failed to format go src due to err: %v", err)
+		return err
 	}
-	err = os.WriteFile(filepath.Join(m.matteDir, "app.go"), []byte(srcS), 0777)
+	err = os.WriteFile(filepath.Join(m.matteDir, AppFile), src, 0666)
 	if err != nil {
-		return fmt.Errorf("failed to write file app.go due to err: %v", err)
+		return fmt.Errorf("failed to write file %v due to err: %v", AppFile, err)
 	}
 	return nil
 }
@@ -131,21 +257,25 @@ func (m *Matte) parseModFile() error {
 	return nil
 }
 
-func (m *Matte) loadProject() (first error) {
+// parses every pkg of the project, files which cannot be parsed are added to the diagnostics,
+// returns an error only if the project dir itself cannot be read
+func (m *Matte) loadProject() error {
 	flags := parser.AllErrors | parser.ParseComments
 	m.Pkgs = []*Pkg{}
 	pkg, dirs, err := m.parseDir(m.wd, flags)
-	if err != nil {
+	if pkg == nil {
 		return err
 	}
+	m.addError(err)
 	m.corePkg = pkg
 	m.Pkgs = append(m.Pkgs, pkg)
 	for {
 		nextRoundDir := []string{}
 		for _, d := range dirs {
 			pkg, newDirs, err := m.parseDir(d, flags)
-			if err != nil {
-				return err
+			m.addError(err)
+			if pkg == nil {
+				continue
 			}
 			m.Pkgs = append(m.Pkgs, pkg)
 			nextRoundDir = append(nextRoundDir, newDirs...)
@@ -155,36 +285,45 @@ func (m *Matte) loadProject() (first error) {
 		}
 		dirs = nextRoundDir
 	}
-	return
+	return nil
 }
 
 func (m *Matte) importPathForDirectory(dir string) string {
-	if dir == "./" {
+	rel, err := filepath.Rel(m.wd, dir)
+	if err != nil || rel == "." {
 		return m.modFile.Module.Mod.Path
 	}
-	return filepath.Join(m.modFile.Module.Mod.Path, dir)
+	return path.Join(m.modFile.Module.Mod.Path, filepath.ToSlash(rel))
+}
+
+// adds the importPath to the imports of the generated src, only once
+func (m *Matte) requireImport(importPath string) {
+	m.imports.add(importPath, assumedPkgName(importPath))
 }
 
-func (m *Matte) processProject() error {
+// processes every file of the project, errors are added to the diagnostics
+func (m *Matte) processProject() {
 	for _, pkg := range m.Pkgs {
 		m.currentPkg = pkg
-		m.imports += fmt.Sprintf(`"%v"`, pkg.ImportPath) + "\n"
-		for _, file := range pkg.Files {
-			err := m.processFile(file)
-			if err != nil {
-				return err
-			}
+		fileNames := make([]string, 0, len(pkg.Files))
+		for fileName := range pkg.Files {
+			fileNames = append(fileNames, fileName)
+		}
+		// files are processed in a stable order so the generated src is too
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
+			m.processFile(pkg.Files[fileName])
 		}
 	}
-	return nil
 }
 
 // extension of parser.parseDir which returns the additional directories inside the project
 // also ignores _test.go files
-// also parses go.mod file if exists, if a pkg has mod file it'll be attached to Pkg
-func (m *Matte) parseDir(dirPath string, mode parser.Mode) (pkg *Pkg, dirs []string, first error) {
+// pkg is nil only if the dir cannot be read, errs has every file which cannot be parsed
+func (m *Matte) parseDir(dirPath string, mode parser.Mode) (pkg *Pkg, dirs []string, errs error) {
 	fset := m.fileSet
 	pkg = &Pkg{
+		ImportPath: m.importPathForDirectory(dirPath),
 		Package: &ast.Package{
 			Files: make(map[string]*ast.File),
 		},
@@ -192,54 +331,55 @@ func (m *Matte) parseDir(dirPath string, mode parser.Mode) (pkg *Pkg, dirs []str
 	list, err := os.ReadDir(dirPath)
 	dirs = make([]string, 0)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("unable to read dir %v due to err: %v", dirPath, err)
 	}
+	diagnostics := Diagnostics{}
 	for _, d := range list {
 		filePath := filepath.Join(dirPath, d.Name())
-		if d.IsDir() && !strings.HasSuffix(d.Name(), MatteDir) {
-			dirs = append(dirs, filepath.Join(dirPath, d.Name()))
+		if d.IsDir() {
+			if !strings.HasSuffix(d.Name(), MatteDir) && !m.isSkippedDir(filePath) {
+				dirs = append(dirs, filepath.Join(dirPath, d.Name()))
+			}
 			continue
 		}
 		if strings.HasSuffix(filePath, "_test.go") || !strings.HasSuffix(filePath, ".go") {
 			continue
 		}
-		if src, err := parser.ParseFile(fset, filePath, nil, mode); err == nil {
-			name := src.Name.Name
-			importPath := m.importPathForDirectory(dirPath)
-			pkg = &Pkg{
-				Name:       name,
-				ImportPath: importPath,
-				Package: &ast.Package{
-					Name:  name,
-					Files: make(map[string]*ast.File),
-				},
-			}
-			pkg.Files[filePath] = src
-		} else if first == nil {
-			first = err
+		src, err := parser.ParseFile(fset, filePath, nil, mode)
+		if err != nil {
+			diagnostics.Add(err)
+			continue
 		}
+		name := src.Name.Name
+		if pkg.Name == "" {
+			pkg.Name = name
+			pkg.Package.Name = name
+		} else if pkg.Name != name {
+			diagnostics.Add(m.resolve(errorAt(src.Name.Pos(), "", "a dir can only have a single pkg",
+				"found pkg %v in %v but the dir already has pkg %v", name, filePath, pkg.Name)))
+			continue
+		}
+		pkg.Files[filePath] = src
 	}
 
-	return
+	return pkg, dirs, diagnostics.Err()
 }
 
 // processes a ast.File and finds each REST handler specific to the passed framework(ex:gin) and
 // parses the swag comments, based on these comments mounts the handler in the framework automatically so you dont have to manually
-func (m *Matte) processFile(astFile *ast.File) (err error) {
+// every error is added to the diagnostics so the rest of the handlers are still processed
+func (m *Matte) processFile(astFile *ast.File) {
+	m.currentFile = astFile
 	for _, fnDecl := range astFile.Decls {
 		// iterate over all the functions in the package but not methods
 
 		if fnDecl, ok := fnDecl.(*ast.FuncDecl); ok && fnDecl.Recv == nil {
 			if fnDecl.Doc != nil {
-				err := m.ProcessFn(fnDecl)
-				if err != nil {
-					return err
-				}
+				m.addError(m.ProcessFn(fnDecl))
 			}
 		}
 
 	}
-	return nil
 }
 
 func (m *Matte) DeferCleanUp() error {
@@ -249,74 +389,124 @@ func (m *Matte) DeferCleanUp() error {
 type Decorator struct {
 	name string
 	args []string
+	// position of the '@' of the decorator
+	pos token.Pos
+	// text of the comment having the decorator
+	comment string
 }
 
+const decoratorHint = `decorators look like @name("arg",...), ex: @path("GET","/users/:id")`
+
 func ParseDecorator(s string) (decorator *Decorator, err error) {
 	expr, err := parser.ParseExpr(s)
 	if err != nil {
-		return nil, err
+		return nil, errorAt(token.NoPos, "", decoratorHint, "invalid decorator: %v", err)
 	}
 	expr2, ok := expr.(*ast.CallExpr)
 	if !ok {
-		return nil, fmt.Errorf("invalid decorator")
+		return nil, errorAt(token.NoPos, "", decoratorHint, "invalid decorator")
 	}
 	nameIdent, ok := expr2.Fun.(*ast.Ident)
 	if !ok {
-		return nil, fmt.Errorf("invalid decorator: name doesnt match any available decorator")
+		return nil, errorAt(token.NoPos, "", decoratorHint, "invalid decorator: name doesnt match any available decorator")
 	}
 	decorator = &Decorator{name: nameIdent.Name, args: []string{}}
 	for _, arg := range expr2.Args {
 		lit, ok := arg.(*ast.BasicLit)
 		if !ok {
-			return nil, fmt.Errorf("invalid decorator: arg %v is not basic lit", arg)
+			return nil, errorAt(token.NoPos, "",
+				"args of a decorator must be string or number literals",
+				"invalid decorator: arg %v is not basic lit", types.ExprString(arg))
 		}
 		decorator.args = append(decorator.args, lit.Value)
 	}
 	return decorator, nil
 }
 
+// returns a Diagnostic pointing at the decorator
+func (d *Decorator) errorf(hint string, format string, args ...any) *Diagnostic {
+	return errorAt(d.pos, d.comment, hint, format, args...)
+}
+
+// returns the args of the decorator as unquoted strings, a nil decorator has no args
+func (d *Decorator) stringArgs() ([]string, error) {
+	if d == nil {
+		return nil, nil
+	}
+	args := []string{}
+	for _, arg := range d.args {
+		s, err := strconv.Unquote(arg)
+		if err != nil {
+			return nil, fmt.Errorf("arg %v of decorator '%v' must be a string", arg, d.name)
+		}
+		args = append(args, s)
+	}
+	return args, nil
+}
+
 func ParseComment(com *ast.CommentGroup) (decorators map[string]*Decorator, err error) {
 	decorators = map[string]*Decorator{}
 	for _, c := range com.List {
+		if _, ok := swagAttribute(c.Text); ok {
+			// swag annotations are processed by swagDecorators
+			continue
+		}
 		splitLine := strings.Split(c.Text, "@")
 		if len(splitLine) == 1 {
 			// not a comment which we should process
-			return
+			continue
 		}
+		// offset of the '@' of the decorator in the comment
+		offset := len(splitLine[0])
 		// ignore the first element
 		splitLine = splitLine[1:]
 
 		for _, possibleDecoratorText := range splitLine {
+			pos := c.Slash + token.Pos(offset)
+			offset += len(possibleDecoratorText) + 1
 			var decorator *Decorator
 			decorator, err = ParseDecorator(possibleDecoratorText)
 			if err != nil {
+				d := diagnosticAt(pos, c.Text, decoratorHint, err)
+				d.Pos, d.Comment = pos, c.Text
+				err = d
 				return
 			}
+			decorator.pos, decorator.comment = pos, c.Text
 			decorators[decorator.name] = decorator
 		}
 	}
 	return
 }
 
+// processes the decorators of the function, errors are Diagnostics pointing at the src which caused them
 func (m *Matte) ProcessFn(fnDecl *ast.FuncDecl) (err error) {
 	decorators := map[string]*Decorator{}
 	if fnDecl.Doc != nil {
 		decorators, err = ParseComment(fnDecl.Doc)
 		if err != nil {
-			return
+			return m.resolve(err)
+		}
+		swagRoutes, err := m.swagDecorators(fnDecl.Doc)
+		if err != nil {
+			return m.resolve(err)
+		}
+		if len(swagRoutes) > 0 {
+			return m.processSwagFn(swagRoutes, decorators, fnDecl)
 		}
 		if len(decorators) == 0 {
 			// not a handler
-			return
+			return nil
 		}
 		pathDecorator := decorators["path"]
 		if pathDecorator == nil {
-			err = fmt.Errorf("a 'path' decorator is required")
-			return
+			return m.resolve(errorAt(fnDecl.Doc.Pos(), fnDecl.Doc.List[0].Text,
+				fmt.Sprintf(`add a path decorator to the doc of %v, ex: @path("GET","/%v")`, fnDecl.Name.Name, strings.ToLower(fnDecl.Name.Name)),
+				"a 'path' decorator is required"))
 		}
-		err := m.ProcessPath(pathDecorator, fnDecl)
+		err = m.ProcessPath(decorators, fnDecl)
 		if err != nil {
-			return err
+			return m.resolve(err)
 		}
 	}
 	return
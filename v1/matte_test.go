@@ -1,57 +1,15 @@
 package matte_test
 
 import (
-	"fmt"
-	"go/ast"
-	"go/format"
-	"go/parser"
 	"go/token"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/ondbyte/matte/v1"
-	"github.com/stretchr/testify/assert"
 	a "github.com/stretchr/testify/assert"
 )
 
-func TestParseParam(t *testing.T) {
-	expr, _ := parser.ParseExpr(`func(a string,x byte,b uint,c *int){}`)
-	flit, ok := expr.(*ast.FuncLit)
-	assert.True(t, ok)
-	for _, f := range flit.Type.Params.List {
-		params, err := matte.ParseParam(f)
-		if assert.NoError(t, err) {
-			for _, p := range params {
-				fmt.Println(p)
-			}
-		}
-	}
-}
-
-func TestGetParamSVerifier(t *testing.T) {
-	s := matte.GetParamsVerifierSrc([]*matte.Param{
-		{
-			Name:     "yadu",
-			Type:     "*int",
-			Required: false,
-		},
-		{
-			Name:     "chinmaya",
-			Type:     "uint",
-			Required: true,
-		},
-		{
-			Name:     "yadu2",
-			Type:     "*int",
-			Required: false,
-		},
-	}, "yadu.HandleHello")
-	sb, err := format.Source([]byte(s))
-	if assert.NoError(t, err) {
-		fmt.Println(string(sb))
-	}
-}
-
 func TestBuild(t *testing.T) {
 	assert := a.New(t)
 	dir, err := filepath.Abs("../test_project")
@@ -62,29 +20,61 @@ func TestBuild(t *testing.T) {
 	}
 }
 
-func TestWithHttpFrameworkHavingDuplicatePath(t *testing.T) {
-	var _ = `
-package corehttp
-
-import "net/http"
+func TestBuildErrorHasPosition(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"handlers/h.go": `package handlers
 
-// @Router /hello [get]
-func HandlePost(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("ondbyte"))
+// Get gets
+// @path("GET")
+func Get() {}
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.Error(err) {
+		return
+	}
+	var d *matte.Diagnostic
+	if assert.ErrorAs(err, &d) {
+		assert.Equal(4, d.Position.Line)
+		assert.Equal(4, d.Position.Column)
+	}
+	lines := strings.Split(err.Error(), "\n")
+	assert.Equal(filepath.Join(dir, "handlers", "h.go")+":4:4: path decorator must have two args", lines[0])
+	assert.Equal("\t"+`// @path("GET")`, lines[1])
+	assert.True(strings.HasPrefix(lines[2], "\thint: "))
 }
 
-// @Router /hello [get]
-func HandleGet(w http.ResponseWriter, r *http.Request) {
-	w.Write([]byte("ondbyte"))
-}
-`
-	/* assert := assert.New(t)
-	_, httpFramework, err := frameworks.NewHttp(":8888")
-	assert.NoError(err)
-	file, err := parser.ParseFile(token.NewFileSet(), "corehttp", errorSrc, parser.AllErrors|parser.ParseComments)
-	assert.NoError(err)
-	err = matte.ProcessFile(file, "yadu", frameworks.Frameworks{httpFramework})
-	expectedErr := `path /hello is already registered for handler yadu.HandlePost so cannot register it with handler yadu.HandleGet again`
-	assert.Error(err, "expected a error")
-	assert.Equal(errors.New(expectedErr), err, "expected error :%v", expectedErr) */
+func TestBuildReportsAllErrors(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"handlers/a.go": `package handlers
+
+// @path("GET")
+func A() {}
+
+// @path("FETCH","/b")
+func B() {}
+`,
+		"handlers/b.go": `package handlers
+
+// @path("GET","/c")
+func C(xs []int) {}
+`,
+		"broken/broken.go": `package broken
+
+func {
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	var diagnostics matte.Diagnostics
+	if !assert.ErrorAs(err, &diagnostics) {
+		return
+	}
+	assert.Len(diagnostics, 4)
+	assert.True(strings.HasSuffix(err.Error(), "\n4 errors"), err.Error())
+	assert.Contains(err.Error(), filepath.Join(dir, "broken", "broken.go")+":3:6:")
+	assert.Contains(err.Error(), filepath.Join(dir, "handlers", "a.go")+":3:4: path decorator must have two args")
+	assert.Contains(err.Error(), filepath.Join(dir, "handlers", "a.go")+":6:4: invalid httpMethod")
+	assert.Contains(err.Error(), filepath.Join(dir, "handlers", "b.go")+":4:8: invalid type []int of param")
 }
@@ -0,0 +1,306 @@
+package matte
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ondbyte/matte/scaffold"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	matteModulePath = "github.com/ondbyte/matte"
+	// version of httprouter the generated app is built with
+	httpRouterVersion = "v1.3.0"
+	// import path of the template of a new service, its imports are rewritten to the module of the service
+	serviceTemplatePath = matteModulePath + "/scaffold/service"
+)
+
+// NewServiceOptions configures NewService
+type NewServiceOptions struct {
+	// version of matte required by the service, the version of this matte by default, which must be set
+	// if that is unknown, ex: this matte is built from a modified tree
+	MatteVersion string
+	// dir of a local matte, required by the service using a replace directive, ex: ../matte
+	MatteReplace string
+}
+
+// NewService creates a service with the module path in dir, having a go.mod, a config.matte.go
+// and the pkgs of the service template of the scaffold, dir must not exist or be empty
+func NewService(dir, modulePath string, options NewServiceOptions) error {
+	if modulePath == "" || strings.ContainsAny(modulePath, " \t\n\\") {
+		return fmt.Errorf("invalid module path %q, ex: github.com/acme/users", modulePath)
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%v already exists and is not empty", dir)
+	}
+	if options.MatteVersion == "" && options.MatteReplace != "" {
+		// the version is replaced anyway
+		options.MatteVersion = "v0.0.0"
+	}
+	if options.MatteVersion == "" {
+		options.MatteVersion = matteVersion()
+	}
+	if options.MatteVersion == "" {
+		return fmt.Errorf("unable to tell the version of this matte as it is built from a modified tree, set the version of matte the service requires, or the dir of a local matte it requires instead")
+	}
+	name := path.Base(modulePath)
+	pkgName := strings.ToLower(goIdent(name, false))
+	goMod := fmt.Sprintf("module %v\n\ngo 1.20\n\nrequire (\n\tgithub.com/julienschmidt/httprouter %v\n\t%v %v\n)\n",
+		modulePath, httpRouterVersion, matteModulePath, options.MatteVersion)
+	if options.MatteReplace != "" {
+		goMod += fmt.Sprintf("\nreplace %v => %v\n", matteModulePath, filepath.ToSlash(options.MatteReplace))
+	}
+	files := map[string]string{
+		"go.mod":     goMod,
+		".gitignore": fmt.Sprintf("/%v/\n/%v\n/%v.exe\n", MatteDir, name, name),
+	}
+	template, _ := fs.Sub(scaffold.Service, "service")
+	err := fs.WalkDir(template, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		src, err := fs.ReadFile(template, file)
+		if err != nil {
+			return fmt.Errorf("unable to read service template %v due to err: %v", file, err)
+		}
+		if strings.HasSuffix(file, ".go") {
+			src, err = renderServiceFile(file, src, pkgName, modulePath)
+			if err != nil {
+				return err
+			}
+		}
+		files[filepath.FromSlash(file)] = string(src)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return fmt.Errorf("unable to mkdir %v due to err: %v", dir, err)
+	}
+	for file, src := range files {
+		err = writeNewFile(filepath.Join(dir, file), src)
+		if err != nil {
+			return err
+		}
+	}
+	return Configure(dir, ConfigureOptions{Title: name})
+}
+
+// renders a go file of the service template, a file at the root of the template is of the pkg of the service,
+// so its pkg is renamed to pkgName, and the imports of the template are rewritten to the module of the service
+func renderServiceFile(file string, src []byte, pkgName, modulePath string) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	f, err := parser.ParseFile(fileSet, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse service template %v due to err: %v", file, err)
+	}
+	if path.Dir(file) == "." {
+		templatePkg := strings.TrimSuffix(f.Name.Name, "_test")
+		f.Name.Name = pkgName + strings.TrimPrefix(f.Name.Name, templatePkg)
+		if f.Doc != nil {
+			for _, comment := range f.Doc.List {
+				comment.Text = strings.Replace(comment.Text, "Package "+templatePkg+" ", "Package "+pkgName+" ", 1)
+			}
+		}
+	}
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if importPath == serviceTemplatePath || strings.HasPrefix(importPath, serviceTemplatePath+"/") {
+			spec.Path.Value = strconv.Quote(modulePath + strings.TrimPrefix(importPath, serviceTemplatePath))
+		}
+	}
+	rendered := &bytes.Buffer{}
+	err = format.Node(rendered, fileSet, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format service template %v due to err: %v", file, err)
+	}
+	return rendered.Bytes(), nil
+}
+
+// returns the version of matte this is built from, empty if it cannot be required, ex: a build of a modified tree
+func matteVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	version := ""
+	if info.Main.Path == matteModulePath {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == matteModulePath {
+			version = dep.Version
+		}
+	}
+	if !semver.IsValid(version) || semver.Build(version) != "" {
+		return ""
+	}
+	return version
+}
+
+// NewHandler adds a decorated handler stub serving the method and path to the pkg at pkgDir, relative to the
+// root of the project, along with a test of it, the pkg is created if it does not exist.
+// returns the paths of the files written
+func NewHandler(project, pkgDir, method, routePath string) ([]string, error) {
+	modulePath, err := GetPackagePathFromGoMod(project)
+	if err != nil {
+		return nil, err
+	}
+	method = strings.ToUpper(method)
+	// the stub must be a handler matte accepts
+	if !isValidHTTPMethod(method) {
+		return nil, fmt.Errorf("method %v is not supported, ex: GET, POST, PUT, PATCH or DELETE", method)
+	}
+	if !strings.HasPrefix(routePath, "/") {
+		return nil, fmt.Errorf("path %v must begin with '/', ex: /users/:id", routePath)
+	}
+	if !filepath.IsLocal(pkgDir) {
+		return nil, fmt.Errorf("pkg dir %v must be relative to the root of the project, ex: users", pkgDir)
+	}
+	wildcards, _ := pathWildcards(routePath)
+	for _, wildcard := range wildcards {
+		if !token.IsIdentifier(wildcard) {
+			return nil, fmt.Errorf("wildcard '%v' of path %v must be a valid go identifier, as it is the name of its param", wildcard, routePath)
+		}
+	}
+
+	// a project which does not load cannot be checked for conflicts, so they are left to the build
+	if m, err := Load(token.NewFileSet(), project); err == nil {
+		for _, route := range m.Routes {
+			if route.Method != method {
+				continue
+			}
+			if conflict := pathConflict(route.Path, routePath); conflict != "" {
+				return nil, fmt.Errorf("path %v conflicts with path %v of handler %v at %v: %v",
+					routePath, route.Path, route.Handler, m.fileSet.Position(route.Pos), conflict)
+			}
+		}
+	}
+
+	dir := filepath.Join(project, pkgDir)
+	pkgName, declared, err := pkgNameOf(dir)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.FieldsFunc(strings.ToLower(method)+" "+strings.NewReplacer(":", " by ", "*", " by ").Replace(routePath), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	name := goIdent(strings.Join(words, " "), true)
+	if declared[name] {
+		return nil, fmt.Errorf("%v is already declared in pkg %v", name, pkgName)
+	}
+	params, args := []string{}, []string{}
+	for _, wildcard := range wildcards {
+		params = append(params, wildcard+" string")
+		args = append(args, strconv.Quote(wildcard))
+	}
+	handlerSrc := fmt.Sprintf(`package %v
+
+import "%v"
+
+// %v handles %v %v
+//
+// @path(%q,%q)
+func %v(%v) error {
+	return web.ErrNotImplemented
+}
+`, pkgName, WebImportPath, name, method, routePath, method, routePath, name, strings.Join(params, ", "))
+	testSrc := fmt.Sprintf(`package %v_test
+
+import (
+	"errors"
+	"testing"
+
+	"%v"
+	"%v"
+)
+
+func Test%v(t *testing.T) {
+	err := %v.%v(%v)
+	if errors.Is(err, web.ErrNotImplemented) {
+		t.Skip("%v.%v is not implemented yet")
+	}
+	if err != nil {
+		t.Fatalf("%v.%v returned err: %%v", err)
+	}
+}
+`, pkgName, WebImportPath, path.Join(modulePath, filepath.ToSlash(pkgDir)), name, pkgName, name, strings.Join(args, ", "),
+		pkgName, name, pkgName, name)
+
+	fileName := strings.ToLower(strings.Join(words, "_"))
+	handlerFile, testFile := filepath.Join(dir, fileName+".go"), filepath.Join(dir, fileName+"_test.go")
+	for _, file := range []string{handlerFile, testFile} {
+		if _, err := os.Stat(file); err == nil {
+			return nil, fmt.Errorf("%v already exists", file)
+		}
+	}
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return nil, fmt.Errorf("unable to mkdir %v due to err: %v", dir, err)
+	}
+	for file, src := range map[string]string{handlerFile: handlerSrc, testFile: testSrc} {
+		err = writeNewFile(file, src)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []string{handlerFile, testFile}, nil
+}
+
+// returns the name of the pkg in the dir, the name of the dir if it has none, along with the names declared by it
+func pkgNameOf(dir string) (string, map[string]bool, error) {
+	pkgName := strings.ToLower(goIdent(filepath.Base(dir), false))
+	declared := map[string]bool{}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("unable to read dir %v due to err: %v", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, entry.Name()), nil, 0)
+		if err != nil {
+			return "", nil, fmt.Errorf("unable to parse %v due to err: %v", filepath.Join(dir, entry.Name()), err)
+		}
+		pkgName = file.Name.Name
+		for name := range file.Scope.Objects {
+			declared[name] = true
+		}
+	}
+	return pkgName, declared, nil
+}
+
+// formats the src if it is go and writes it into a new file
+func writeNewFile(file, src string) error {
+	if strings.HasSuffix(file, ".go") {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return fmt.Errorf("failed to format the src of %v due to err: %v", file, err)
+		}
+		src = string(formatted)
+	}
+	err := os.MkdirAll(filepath.Dir(file), 0777)
+	if err != nil {
+		return fmt.Errorf("unable to mkdir %v due to err: %v", filepath.Dir(file), err)
+	}
+	err = os.WriteFile(file, []byte(src), 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", file, err)
+	}
+	return nil
+}
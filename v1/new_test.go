@@ -0,0 +1,83 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestNewServiceAndHandler(t *testing.T) {
+	assert := a.New(t)
+	repo, _ := filepath.Abs("..")
+	dir := filepath.Join(t.TempDir(), "users")
+	err := matte.NewService(dir, "github.com/acme/users", matte.NewServiceOptions{MatteReplace: repo})
+	if !assert.NoError(err) {
+		return
+	}
+	goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	assert.Contains(string(goMod), "\tgithub.com/ondbyte/matte v0.0.0\n")
+	assert.Contains(string(goMod), "replace github.com/ondbyte/matte => "+filepath.ToSlash(repo))
+	assert.FileExists(filepath.Join(dir, matte.ConfigFile))
+	doc, _ := os.ReadFile(filepath.Join(dir, "doc.go"))
+	assert.Equal("// Package users is a service, matte generates its app from the handlers of its pkgs, run it using matte run\npackage users\n", string(doc))
+	helloTest, _ := os.ReadFile(filepath.Join(dir, "hello", "hello_test.go"))
+	assert.Contains(string(helloTest), `"github.com/acme/users/hello"`)
+	err = matte.NewService(dir, "github.com/acme/users", matte.NewServiceOptions{})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "already exists and is not empty")
+	}
+
+	files, err := matte.NewHandler(dir, "accounts", "get", "/accounts/:id")
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]string{filepath.Join(dir, "accounts", "get_accounts_by_id.go"), filepath.Join(dir, "accounts", "get_accounts_by_id_test.go")}, files)
+	_, err = matte.NewHandler(dir, "accounts", "GET", "/accounts/:name")
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "path /accounts/:name conflicts with path /accounts/:id of handler accounts.GetAccountsByID")
+	}
+	for _, method := range []string{"GO", "HEAD"} {
+		_, err = matte.NewHandler(dir, "accounts", method, "/accounts")
+		if assert.Error(err, method) {
+			assert.Contains(err.Error(), "method "+method+" is not supported")
+		}
+	}
+	routes, err := matte.ListRoutes(token.NewFileSet(), dir, matte.RouteFilter{})
+	if assert.NoError(err) && assert.Len(routes, 2) {
+		assert.Equal("accounts.GetAccountsByID", routes[0].Handler)
+		assert.Equal("hello.Greet", routes[1].Handler)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain is not installed")
+	}
+	goSum, _ := os.ReadFile(filepath.Join(repo, "go.sum"))
+	os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0666)
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	assert.NoError(err, string(output))
+}
+
+func TestNewServiceRequiresAMatteVersion(t *testing.T) {
+	assert := a.New(t)
+	// the test binary is built from this tree, so it has no version
+	dir := filepath.Join(t.TempDir(), "users")
+	err := matte.NewService(dir, "github.com/acme/users", matte.NewServiceOptions{})
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "unable to tell the version of this matte")
+	}
+	assert.NoDirExists(dir)
+
+	err = matte.NewService(dir, "github.com/acme/users", matte.NewServiceOptions{MatteVersion: "v1.2.3"})
+	if assert.NoError(err) {
+		goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+		assert.Contains(string(goMod), "\tgithub.com/ondbyte/matte v1.2.3\n")
+		assert.NotContains(string(goMod), "replace")
+	}
+}
@@ -1,5 +1,220 @@
 package matte
 
-import "github.com/getkin/kin-openapi/openapi3"
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
-type OA openapi3.T
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+)
+
+const (
+	OpenAPIJSONFile = "openapi.json"
+	OpenAPIYAMLFile = "openapi.yaml"
+
+	problemComponent = "Problem"
+)
+
+// OpenAPI returns the openapi 3 document describing every route of the project
+func (m *Matte) OpenAPI() *openapi3.T {
+	g := newSchemaGen(m)
+	doc := &openapi3.T{
+		OpenAPI:    "3.0.3",
+		Info:       &openapi3.Info{},
+		Paths:      openapi3.NewPaths(),
+		Components: &openapi3.Components{Schemas: g.components},
+	}
+	m.applyGeneralAPIInfo(doc)
+	for _, route := range m.Routes {
+		openAPIPath := toOpenAPIPath(route.Path)
+		pathItem := doc.Paths.Value(openAPIPath)
+		if pathItem == nil {
+			pathItem = &openapi3.PathItem{}
+			doc.Paths.Set(openAPIPath, pathItem)
+		}
+		pathItem.SetOperation(route.Method, g.operation(route))
+	}
+	if len(m.Routes) > 0 {
+		g.components[problemComponent] = openapi3.NewSchemaRef("", problemSchema())
+	}
+	return doc
+}
+
+// returns the operation of the route
+func (g *schemaGen) operation(route *Route) *openapi3.Operation {
+	op := openapi3.NewOperation()
+	op.OperationID = route.Handler
+	op.Tags = route.Tags
+	op.Description = route.Doc
+	op.Summary = route.Summary
+	op.Deprecated = route.Deprecated
+	if len(route.Security) > 0 {
+		security := securityRequirements(route.Security)
+		op.Security = &security
+	}
+	responses := openapi3.NewResponsesWithCapacity(4)
+	for _, param := range route.Params {
+		schema := g.schemaOf(param.typeExpr, route.Pkg, route.File)
+		switch param.location() {
+		case InBody:
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+				WithRequired(param.Required).
+				WithContent(openapi3.NewContentWithJSONSchemaRef(schema))}
+			responses.Set(strconv.Itoa(http.StatusRequestEntityTooLarge), problemResponse(http.StatusRequestEntityTooLarge))
+			responses.Set(strconv.Itoa(http.StatusUnsupportedMediaType), problemResponse(http.StatusUnsupportedMediaType))
+		default:
+			op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: &openapi3.Parameter{
+				Name:     param.Name,
+				In:       string(param.location()),
+				Required: param.Required,
+				Schema:   schema,
+			}})
+		}
+		responses.Set(strconv.Itoa(http.StatusBadRequest), problemResponse(http.StatusBadRequest))
+	}
+
+	success := openapi3.NewResponse().WithDescription(http.StatusText(route.Response.Status))
+	if len(route.Response.Results) == 1 {
+		result := route.Response.Results[0]
+		schema := openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		if isJSONContentType(route.Response.ContentType) {
+			schema = g.schemaOf(result.typeExpr, route.Pkg, route.File)
+		}
+		success.Content = openapi3.NewContentWithSchemaRef(schema, []string{route.Response.ContentType})
+	}
+	responses.Set(strconv.Itoa(route.Response.Status), &openapi3.ResponseRef{Value: success})
+	if route.Response.ReturnsError {
+		responses.Set("default", problemResponse(0))
+	}
+	op.Responses = responses
+	return op
+}
+
+// converts the wildcards of the path into openapi templates, ex: /users/:id becomes /users/{id}
+func toOpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isWildcard(segment) {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// response having the problem document written by the web pkg, status 0 is any error
+func problemResponse(status int) *openapi3.ResponseRef {
+	description := "error"
+	if status != 0 {
+		description = http.StatusText(status)
+	}
+	response := openapi3.NewResponse().WithDescription(description)
+	response.Content = openapi3.NewContentWithSchemaRef(
+		openapi3.NewSchemaRef("#/components/schemas/"+problemComponent, nil),
+		[]string{"application/problem+json"},
+	)
+	return &openapi3.ResponseRef{Value: response}
+}
+
+// schema of web.Problem
+func problemSchema() *openapi3.Schema {
+	invalidParam := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("in", openapi3.NewStringSchema()).
+		WithProperty("reason", openapi3.NewStringSchema())
+	schema := openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema()).
+		WithProperty("title", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewInt32Schema()).
+		WithProperty("detail", openapi3.NewStringSchema()).
+		WithProperty("instance", openapi3.NewStringSchema()).
+		WithProperty("invalid-params", openapi3.NewArraySchema().WithItems(invalidParam))
+	schema.Title = "RFC 7807 problem details"
+	schema.Required = []string{"title", "status"}
+	return schema
+}
+
+// writes the openapi document of the project into the matte dir as json and yaml
+func (m *Matte) writeOpenAPI() error {
+	doc := m.OpenAPI()
+	jsonBytes, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi document due to err: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(m.matteDir, OpenAPIJSONFile), jsonBytes, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", OpenAPIJSONFile, err)
+	}
+	yamlBytes, err := yaml.JSONToYAML(jsonBytes)
+	if err != nil {
+		return fmt.Errorf("failed to encode openapi document as yaml due to err: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(m.matteDir, OpenAPIYAMLFile), yamlBytes, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", OpenAPIYAMLFile, err)
+	}
+	return nil
+}
+
+// returns the src mounting the openapi document and the api explorer, if the config enables them
+func (m *Matte) docsSrc() string {
+	docs := m.config.Docs
+	if !docs.Enabled {
+		return ""
+	}
+	m.requireImport(WebImportPath)
+	environments := ""
+	for _, env := range docs.Environments {
+		environments += strconv.Quote(env) + ","
+	}
+	return fmt.Sprintf(`if web.EnabledIn(%v) {
+		router.Handler("GET", %q, web.SpecHandler(openAPISpec))
+		router.Handler("GET", %q, web.DocsHandler(%q))
+	}`, environments, docs.SpecPath, docs.UIPath, docs.SpecPath)
+}
+
+// adds a diagnostic for each path served by matte itself, like the docs and version paths,
+// which is invalid or conflicts with a route or another such path
+func (m *Matte) checkBuiltinPaths() {
+	// name of each builtin path by the path
+	builtins := map[string]string{}
+	paths := []string{}
+	if docs := m.config.Docs; docs.Enabled {
+		builtins[docs.SpecPath], builtins[docs.UIPath] = "docs", "docs"
+		paths = append(paths, docs.SpecPath, docs.UIPath)
+		if docs.SpecPath == docs.UIPath {
+			m.addError(errorAt(token.NoPos, "", "change the docs paths in your config",
+				"docs spec and ui cannot both be served at %v", docs.SpecPath))
+		}
+	}
+	if versionPath := m.config.VersionPath; versionPath != "" {
+		if builtins[versionPath] != "" {
+			m.addError(errorAt(token.NoPos, "", "change the version path in your config",
+				"version cannot be served at %v which is a %v path", versionPath, builtins[versionPath]))
+		}
+		builtins[versionPath] = "version"
+		paths = append(paths, versionPath)
+	}
+	for _, builtinPath := range paths {
+		name := builtins[builtinPath]
+		if !strings.HasPrefix(builtinPath, "/") {
+			m.addError(errorAt(token.NoPos, "", fmt.Sprintf("change the %v paths in your config", name),
+				"%v path %q must start with '/'", name, builtinPath))
+			continue
+		}
+		for _, route := range m.Routes {
+			if route.Method != http.MethodGet {
+				continue
+			}
+			if conflict := pathConflict(route.Path, builtinPath); conflict != "" {
+				m.addError(errorAt(route.Pos, "", fmt.Sprintf("change the %v paths in your config or disable them", name),
+					"path %v of handler %v conflicts with %v path %v: %v", route.Path, route.Handler, name, builtinPath, conflict))
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package matte_test
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildWritesOpenAPI(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+import "github.com/ondbyte/test/models"
+
+// GetUser returns the user having the id
+// @path("GET","/users/:id")
+// @query("fields")
+func GetUser(id int, fields *string) (*models.User, error) { return nil, nil }
+
+// CreateUser creates a user
+// @path("POST","/users")
+// @body("user")
+// @status(201)
+func CreateUser(user models.User) (*models.User, error) { return nil, nil }
+
+// @path("GET","/ping")
+func Ping() (textResponse string) { return "pong" }
+`,
+		"models/models.go": `
+package models
+
+import "time"
+
+type Base struct {
+	CreatedAt time.Time ` + "`json:\"createdAt\"`" + `
+}
+
+// User of the app
+type User struct {
+	Base
+	Name    string            ` + "`json:\"name\"`" + `
+	Email   *string           ` + "`json:\"email\"`" + `
+	Tags    []string          ` + "`json:\"tags,omitempty\"`" + `
+	Friends []*User           ` + "`json:\"friends\"`" + `
+	Meta    map[string]int    ` + "`json:\"meta\"`" + `
+	secret  string
+	Ignored string            ` + "`json:\"-\"`" + `
+}
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join(dir, matte.MatteDir, matte.OpenAPIJSONFile))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(doc.Validate(context.Background()))
+	_, err = os.Stat(filepath.Join(dir, matte.MatteDir, matte.OpenAPIYAMLFile))
+	assert.NoError(err)
+
+	getUser := doc.Paths.Find("/users/{id}").Get
+	if assert.NotNil(getUser) {
+		assert.Equal("GetUser returns the user having the id", getUser.Summary)
+		assert.Equal("path", getUser.Parameters.GetByInAndName("path", "id").In)
+		assert.True(getUser.Parameters.GetByInAndName("path", "id").Required)
+		assert.False(getUser.Parameters.GetByInAndName("query", "fields").Required)
+		assert.NotNil(getUser.Responses.Status(400))
+		assert.NotNil(getUser.Responses.Default())
+		assert.Equal("#/components/schemas/models.User",
+			getUser.Responses.Status(200).Value.Content.Get("application/json").Schema.Ref)
+	}
+	createUser := doc.Paths.Find("/users").Post
+	if assert.NotNil(createUser) {
+		assert.True(createUser.RequestBody.Value.Required)
+		assert.NotNil(createUser.Responses.Status(201))
+	}
+	user := doc.Components.Schemas["models.User"].Value
+	assert.ElementsMatch([]string{"createdAt", "name", "email", "tags", "friends", "meta"}, keys(user.Properties))
+	assert.ElementsMatch([]string{"createdAt", "name", "friends", "meta"}, user.Required)
+	assert.Equal("date-time", user.Properties["createdAt"].Value.Format)
+	assert.Equal("#/components/schemas/models.User", user.Properties["friends"].Value.Items.Ref)
+	assert.Equal("text/plain", keys(doc.Paths.Find("/ping").Get.Responses.Status(200).Value.Content)[0])
+}
+
+func TestBuildMountsDocs(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"pages/pages.go": `
+package pages
+
+// @path("GET","/pages/:name")
+func Page(name string) (htmlResponse string) { return "" }
+`})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	app := readApp(t, dir)
+	assert.Contains(app, "//go:embed openapi.json")
+	assert.Contains(app, `if web.EnabledIn("development", "staging") {`)
+	assert.Contains(app, `router.Handler("GET", "/docs", web.DocsHandler("/openapi.json"))`)
+
+	dir = newTestProject(t, map[string]string{"pages/pages.go": `
+package pages
+
+// @path("GET","/:name")
+func Page(name string) (htmlResponse string) { return "" }
+`})
+	err = matte.Build(token.NewFileSet(), dir)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), "path /:name of handler pages.Page conflicts with docs path /openapi.json")
+	}
+}
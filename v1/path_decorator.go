@@ -3,7 +3,9 @@ package matte
 import (
 	"fmt"
 	"go/ast"
-	"net/http"
+	"go/token"
+	"go/types"
+	"strconv"
 	"strings"
 )
 
@@ -38,146 +40,407 @@ func isValidHTTPMethod(method string) bool {
 }
 
 func GetParamsVerifierSrc(params []*Param, caller string) string {
-	s := "var err error"
+	s, args, _ := getParamsSrc(params)
+	s += fmt.Sprintf("%v(%v)", caller, args)
+	s += "\n"
+	return s
+}
+
+// returns the src which reads and verifies the params and the args to call the handler with,
+// every invalid param is collected and responded with a single web.WriteValidationProblem,
+// declaresErr tells whether the src declares err, it does only if it uses it
+func getParamsSrc(params []*Param) (s string, args string, declaresErr bool) {
 	newLine := func() {
 		s += "\n"
 	}
+	s += `invalidParams:=[]web.InvalidParam{}`
 	newLine()
-	s += fmt.Sprintf(`errS:=""`)
-	newLine()
-	args := ""
 	for _, param := range params {
-		paramName := param.Name
-		paramType := param.Type
-		paramTypeWithoutStar := strings.Trim(paramType, "*")
-		s += fmt.Sprintf(`%vS:=p.ByName("%v")`, paramName, paramName)
+		if param.location() == InQuery {
+			s += `queryValues:=r.URL.Query()`
+			newLine()
+			break
+		}
+	}
+	bodySrc := ""
+	for _, param := range params {
+		local, raw := param.local(), param.rawLocal()
+		paramTypeWithoutStar := strings.Trim(param.Type, "*")
+		if param.Required {
+			args += "*"
+		}
+		args += local + ","
+		if param.location() == InBody {
+			// body is decoded only once rest of the params are valid
+			bodySrc = getBodyDecoderSrc(param)
+			declaresErr = true
+			continue
+		}
+		switch param.location() {
+		case InQuery:
+			s += fmt.Sprintf(`%v:=queryValues.Get("%v")`, raw, param.Name)
+		default:
+			s += fmt.Sprintf(`%v:=p.ByName("%v")`, raw, param.Name)
+		}
 		newLine()
 		if param.Required {
-			s += fmt.Sprintf(`if %vS == "" {`, paramName)
+			s += fmt.Sprintf(`if %v == "" {
+				invalidParams = append(invalidParams, web.InvalidParam{Name: "%v", In: "%v", Reason: "is required"})
+			}`, raw, param.Name, param.location())
+			newLine()
+			s += fmt.Sprintf(`%v:=new(%v)`, local, paramTypeWithoutStar)
+			newLine()
+			s += fmt.Sprintf(`if %v != "" {`, raw)
+			newLine()
+		} else {
+			// an optional param is nil unless it is present, same as an optional body
+			s += fmt.Sprintf(`var %v *%v`, local, paramTypeWithoutStar)
 			newLine()
-			s += fmt.Sprintf(`errS += fmt.Sprintf("param '%v' is required\n")
-			}`, paramName)
+			s += fmt.Sprintf(`if %v != "" {
+				%v = new(%v)`, raw, local, paramTypeWithoutStar)
 			newLine()
 		}
-		s += fmt.Sprintf(`%v:=new(%v)`, paramName, paramTypeWithoutStar)
-		newLine()
-		s += fmt.Sprintf(`if %vS != "" {`, paramName)
-		newLine()
-		s += fmt.Sprintf(`err=json.Unmarshal([]byte(%vS), %v)`, paramName, paramName)
-		newLine()
-		s += fmt.Sprintf(`if err!=nil{
-			errS+="param value " + %vS +" cannot be Unmarshalled into type %v\n"
+		if param.scalarKind() == "string" {
+			// raw strings are not valid json, so they are taken as is
+			value := raw
+			if paramTypeWithoutStar != "string" {
+				value = fmt.Sprintf("%v(%v)", paramTypeWithoutStar, value)
+			}
+			s += fmt.Sprintf(`*%v=%v
+			}`, local, value)
+			newLine()
+		} else {
+			declaresErr = true
+			s += fmt.Sprintf(`err=json.Unmarshal([]byte(%v), %v)`, raw, local)
+			newLine()
+			s += fmt.Sprintf(`if err!=nil{
+			invalidParams = append(invalidParams, web.InvalidParam{Name: "%v", In: "%v", Reason: "value '" + %v + "' cannot be parsed as %v"})
 		}
-		}`, paramName, paramTypeWithoutStar)
-		newLine()
-		s += fmt.Sprintf(`if errS!=""{
-			http.Error(w,errS,http.StatusTeapot)
-			return
-		}`)
-		newLine()
-		if param.Required {
-			args += "*"
+		}`, param.Name, param.location(), raw, paramTypeWithoutStar)
+			newLine()
 		}
-		args += paramName + ","
 	}
-	s += fmt.Sprintf("%v(%v)", caller, args)
+	s += `if len(invalidParams) > 0 {
+		web.WriteValidationProblem(w, r, invalidParams)
+		return
+	}`
 	newLine()
-	return s
+	s += bodySrc
+	newLine()
+	if declaresErr {
+		s = "var err error\n" + s
+	}
+	return s, args, declaresErr
 }
 
-func a(w http.ResponseWriter) {
-	http.Error(w, "", http.StatusTeapot)
+// returns the src which decodes the json request body into the param
+func getBodyDecoderSrc(param *Param) string {
+	paramTypeWithoutStar := strings.Trim(param.Type, "*")
+	maxBytes := param.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	onEmpty := fmt.Sprintf(`web.WriteValidationProblem(w, r, []web.InvalidParam{{Name: "%v", In: "body", Reason: "is required"}})
+		return`, param.Name)
+	if !param.Required {
+		onEmpty = fmt.Sprintf(`%v = nil`, param.local())
+	}
+	s := fmt.Sprintf(`if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		web.WriteProblem(w, r, web.NewProblem(http.StatusUnsupportedMediaType, "request body must be of content type application/json"))
+		return
+	}
+	%[5]v := new(%[2]v)
+	err = json.NewDecoder(http.MaxBytesReader(w, r.Body, %[3]v)).Decode(%[5]v)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			web.WriteProblem(w, r, web.NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not be larger than %%v bytes", maxBytesErr.Limit)))
+			return
+		case errors.Is(err, io.EOF):
+			%[4]v
+		default:
+			web.WriteValidationProblem(w, r, []web.InvalidParam{{Name: "%[1]v", In: "body", Reason: "cannot be decoded into type %[2]v: " + err.Error()}})
+			return
+		}
+	}`, param.Name, paramTypeWithoutStar, maxBytes, onEmpty, param.local())
+	if !param.Required {
+		// an optional body can be left out entirely, including its content type
+		s = fmt.Sprintf(`var %[1]v *%[2]v
+		if r.ContentLength != 0 {
+			%[3]v
+		}`, param.local(), paramTypeWithoutStar, strings.Replace(s, param.local()+" := new(", param.local()+" = new(", 1))
+	}
+	return s
 }
 
+// ProcessPath generates the router registration for the handler, decorators must contain a 'path' decorator
 func (m *Matte) ProcessPath(
-	pathDecorator *Decorator,
+	decorators map[string]*Decorator,
 	handler *ast.FuncDecl,
 ) (err error) {
 	caller := m.currentPkg.Name + "." + handler.Name.Name
+	pathDecorator := decorators["path"]
 	if len(pathDecorator.args) != 2 {
-		err = fmt.Errorf("path decorator must have two args")
+		err = pathDecorator.errorf(pathHint, "path decorator must have two args")
 		return
 	}
-	httpMethod, path := strings.Trim(pathDecorator.args[0], `"`), pathDecorator.args[1]
+	httpMethod := strings.ToUpper(strings.Trim(pathDecorator.args[0], `"`))
 	if !isValidHTTPMethod(httpMethod) {
-		err = fmt.Errorf("invalid httpMethod")
+		err = pathDecorator.errorf("http method must be one of GET, POST, PUT, DELETE, PATCH or OPTIONS",
+			"invalid httpMethod %v", pathDecorator.args[0])
+		return
+	}
+	path, err := strconv.Unquote(pathDecorator.args[1])
+	if err != nil || !strings.HasPrefix(path, "/") {
+		err = pathDecorator.errorf(pathHint, "path %v must be a string starting with '/'", pathDecorator.args[1])
 		return
 	}
 	params := []*Param{}
 	for _, param := range handler.Type.Params.List {
 		_params, err := ParseParam(param)
 		if err != nil {
-			return fmt.Errorf("err while ParseForm : \n%v", err)
+			return err
 		}
 		params = append(params, _params...)
 	}
-	verifierSrc := GetParamsVerifierSrc(params, caller)
+	queryDecorator := decorators["query"]
+	queryNames, err := queryDecorator.stringArgs()
+	if err != nil {
+		return queryDecorator.errorf(`ex: @query("limit","offset")`, "invalid query decorator: %v", err)
+	}
+	for _, name := range queryNames {
+		param := findParam(params, name)
+		if param == nil {
+			return queryDecorator.errorf(fmt.Sprintf("add a param '%v' to %v or remove it from the decorator", name, caller),
+				"query decorator names '%v' which is not a param of %v", name, caller)
+		}
+		param.In = InQuery
+	}
+	if bodyDecorator := decorators["body"]; bodyDecorator != nil {
+		err = applyBodyDecorator(bodyDecorator, params, caller)
+		if err != nil {
+			return err
+		}
+		m.requireImport("encoding/json")
+		m.requireImport("errors")
+		m.requireImport("fmt")
+		m.requireImport("io")
+		m.requireImport("mime")
+	}
+	err = m.checkPathParams(pathDecorator, path, params, caller)
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		if param.location() != InBody && param.scalarKind() != "string" {
+			// parsed from json
+			m.requireImport("encoding/json")
+		}
+		param.Type, err = m.qualifyType(param.Type)
+		if err != nil {
+			return errorAt(param.pos, "", "import the pkg of the type in the file of the handler",
+				"param '%v' of %v has invalid type: %v", param.Name, caller, err)
+		}
+	}
+	response, err := ParseResponse(decorators, handler)
+	if err != nil {
+		return err
+	}
+	route := &Route{
+		Method:   httpMethod,
+		Path:     path,
+		Handler:  caller,
+		Pkg:      m.currentPkg,
+		File:     m.currentFile,
+		Doc:      docText(handler.Doc),
+		Params:   params,
+		Response: response,
+		Pos:      pathDecorator.pos,
+	}
+	err = applyDocDecorators(route, decorators)
+	if err != nil {
+		return err
+	}
+	err = m.addRoute(route)
+	if err != nil {
+		return err
+	}
+	m.requireImport(WebImportPath)
+	pkgName := m.imports.add(m.currentPkg.ImportPath, m.currentPkg.Name)
+	paramsSrc, args, declaresErr := getParamsSrc(params)
+	if !declaresErr && response.ReturnsError && len(response.Results) == 0 {
+		// the error of such a handler is assigned to err
+		paramsSrc = "var err error\n" + paramsSrc
+	}
+	responseSrc := GetResponseSrc(response, fmt.Sprintf("%v.%v(%v)", pkgName, handler.Name.Name, args))
+	m.requireResponseImports(response)
 
 	src := fmt.Sprintf(`
-	router.Handle("%v",%v,func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	router.Handle("%v",%q,func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		%v
 		%v
 	})
-	`, httpMethod, path, verifierSrc)
+	`, httpMethod, path, paramsSrc, responseSrc)
 	m.src += src
 	return nil
 }
 
+// ParamLocation is the part of the request a handler param is read from
+type ParamLocation string
+
+const (
+	InPath  ParamLocation = "path"
+	InQuery ParamLocation = "query"
+	InBody  ParamLocation = "body"
+)
+
+const (
+	pathHint = `ex: @path("GET","/users/:id")`
+	bodyHint = `ex: @body("user") or @body("user",4096) to limit the body to 4096 bytes`
+)
+
+// max size of a request body unless the body decorator says otherwise
+const DefaultMaxBodyBytes = 1 << 20
+
 type Param struct {
 	Name     string
 	Type     string
 	Required bool
+	// where the value of the param comes from, empty means InPath
+	In ParamLocation
+	// max size of the request body in bytes, only used when In is InBody
+	MaxBytes int64
+	// position of the param in the handler
+	pos token.Pos
+	// type of the param as declared in the handler
+	typeExpr ast.Expr
+	// string, bool or number type a path or query param is parsed as, ex: string for type ID string,
+	// the type itself if empty
+	kind string
+}
+
+// returns the string, bool or number type the path or query param is parsed as
+func (p *Param) scalarKind() string {
+	if p.kind == "" {
+		return strings.TrimLeft(p.Type, "*")
+	}
+	return p.kind
+}
+
+// name of the local holding the param in the generated src, prefixed so it collides neither with the other locals
+// of the src nor with the pkgs it uses
+func (p *Param) local() string {
+	return "param_" + p.Name
+}
+
+// name of the local holding the string value of the path or query param in the generated src
+func (p *Param) rawLocal() string {
+	return "raw_" + p.Name
+}
+
+func (p *Param) location() ParamLocation {
+	if p.In == "" {
+		return InPath
+	}
+	return p.In
+}
+
+// marks the param named by the body decorator to be decoded from the json request body,
+// ex: @body("user") or @body("user",4096) to limit the body to 4096 bytes
+func applyBodyDecorator(bodyDecorator *Decorator, params []*Param, caller string) error {
+	if len(bodyDecorator.args) == 0 || len(bodyDecorator.args) > 2 {
+		return bodyDecorator.errorf(bodyHint, "body decorator must have a param name and optionally the max body size in bytes")
+	}
+	name, err := strconv.Unquote(bodyDecorator.args[0])
+	if err != nil {
+		return bodyDecorator.errorf(bodyHint, "first arg of body decorator must be a string")
+	}
+	param := findParam(params, name)
+	if param == nil {
+		return bodyDecorator.errorf(fmt.Sprintf("add a param '%v' to %v or name an existing one", name, caller),
+			"body decorator names '%v' which is not a param of %v", name, caller)
+	}
+	if param.In == InQuery {
+		return bodyDecorator.errorf("remove the param from either the query or the body decorator",
+			"param '%v' of %v cannot be read from both query and body", name, caller)
+	}
+	param.In = InBody
+	if len(bodyDecorator.args) == 2 {
+		param.MaxBytes, err = strconv.ParseInt(bodyDecorator.args[1], 0, 64)
+		if err != nil || param.MaxBytes <= 0 {
+			return bodyDecorator.errorf(bodyHint, "second arg of body decorator must be a positive number of bytes")
+		}
+	}
+	return nil
+}
+
+func findParam(params []*Param, name string) *Param {
+	for _, p := range params {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
 }
 
 func ParseParam(field *ast.Field) (params []*Param, err error) {
 	params = []*Param{}
-	var id *ast.Ident
 	var paramType string
+	typeExpr := field.Type
 	starExpr, ok := field.Type.(*ast.StarExpr)
 	required := true
 	if ok {
 		paramType += "*"
 		required = false
-		id, _ = starExpr.X.(*ast.Ident)
-	} else {
-		id, _ = field.Type.(*ast.Ident)
+		typeExpr = starExpr.X
 	}
-	if id == nil {
-		return nil, fmt.Errorf("invalid type of param %v", field)
+	typeName := typeNameOf(typeExpr)
+	if typeName == "" {
+		return nil, errorAt(field.Pos(), "", "params must be of a named type like int, string, User or models.User",
+			"invalid type %v of param", types.ExprString(field.Type))
 	}
-	paramType += id.Name
+	paramType += typeName
 	for _, name := range field.Names {
-		params = append(params, &Param{Name: name.Name, Type: paramType, Required: required})
+		params = append(params, &Param{Name: name.Name, Type: paramType, Required: required, In: InPath, pos: name.Pos(), typeExpr: field.Type})
 	}
 	return params, nil
 }
 
-/*
-func (o *OA) ParsePathDecorator(name string, args []string) error {
-	decorator, _ := ParseDecorator(`path("GET","yadhu")`)
-	if len(nil) != len(args) {
-		return fmt.Errorf("needs two args")
-	}
-	if len(args) != 2 {
-		return fmt.Errorf("path decorator needs two arguments")
-	}
-	if !isValidHTTPMethod(args[0]) {
-		return fmt.Errorf("first argument must be a valid http method")
+// returns name of the type as written in the src, ex: "int", "User" or "models.User",
+// returns empty string if expr is not a named type
+func typeNameOf(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		pkgIdent, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		return pkgIdent.Name + "." + expr.Sel.Name
 	}
-	i := o.Paths.Find(args[1])
-	if i == nil {
-		i = &openapi3.PathItem{}
+	return ""
+}
+
+// qualifies the type name written in the current pkg so it can be used from the generated src,
+// ex: "*User" becomes "*handlers.User" and "models.User" requires the import of models
+func (m *Matte) qualifyType(paramType string) (string, error) {
+	star := ""
+	if strings.HasPrefix(paramType, "*") {
+		star = "*"
 	}
-	switch args[0] {
-	case "GET":
-		{
-			if i.Get != nil {
-				return fmt.Errorf("path '%v' already has a handler for method type '%v'", args[1], args[0])
-			}
-			op := openapi3.NewOperation()
-			i.Get = op
+	typeName := strings.TrimPrefix(paramType, "*")
+	pkgName, name, isSelector := strings.Cut(typeName, ".")
+	if !isSelector {
+		if _, isBuiltin := types.Universe.Lookup(typeName).(*types.TypeName); isBuiltin {
+			return paramType, nil
 		}
-
+		return star + m.imports.add(m.currentPkg.ImportPath, m.currentPkg.Name) + "." + typeName, nil
 	}
-	return nil
+	importPath, ok := m.importPathOf(m.currentFile, pkgName)
+	if !ok {
+		return "", fmt.Errorf("package %v of type %v is not imported", pkgName, typeName)
+	}
+	return star + m.imports.add(importPath, pkgName) + "." + name, nil
 }
-*/
@@ -0,0 +1,248 @@
+package matte_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	"github.com/stretchr/testify/assert"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestParseParam(t *testing.T) {
+	expr, _ := parser.ParseExpr(`func(a string,x byte,b uint,c *int){}`)
+	flit, ok := expr.(*ast.FuncLit)
+	assert.True(t, ok)
+	for _, f := range flit.Type.Params.List {
+		params, err := matte.ParseParam(f)
+		if assert.NoError(t, err) {
+			for _, p := range params {
+				fmt.Println(p)
+			}
+		}
+	}
+}
+
+func TestGetParamSVerifier(t *testing.T) {
+	s := matte.GetParamsVerifierSrc([]*matte.Param{
+		{
+			Name:     "yadu",
+			Type:     "*int",
+			Required: false,
+		},
+		{
+			Name:     "chinmaya",
+			Type:     "uint",
+			Required: true,
+		},
+		{
+			Name:     "yadu2",
+			Type:     "*int",
+			Required: false,
+		},
+	}, "yadu.HandleHello")
+	sb, err := format.Source([]byte(s))
+	if assert.NoError(t, err) {
+		fmt.Println(string(sb))
+	}
+}
+
+func TestGetParamsVerifierSrcWithQuery(t *testing.T) {
+	assert := a.New(t)
+	s := matte.GetParamsVerifierSrc([]*matte.Param{
+		{
+			Name:     "id",
+			Type:     "int",
+			Required: true,
+			In:       matte.InPath,
+		},
+		{
+			Name:     "limit",
+			Type:     "*int",
+			Required: false,
+			In:       matte.InQuery,
+		},
+		{
+			Name:     "filter",
+			Type:     "string",
+			Required: true,
+			In:       matte.InQuery,
+		},
+	}, "yadu.HandleList")
+	sb, err := format.Source([]byte(s))
+	if !assert.NoError(err) {
+		return
+	}
+	src := string(sb)
+	assert.Contains(src, `raw_id := p.ByName("id")`)
+	assert.Contains(src, `raw_limit := queryValues.Get("limit")`)
+	assert.Contains(src, `raw_filter := queryValues.Get("filter")`)
+	assert.Contains(src, `*param_filter = raw_filter`)
+	assert.Contains(src, `web.InvalidParam{Name: "filter", In: "query", Reason: "is required"}`)
+	assert.Contains(src, `web.WriteValidationProblem(w, r, invalidParams)`)
+	assert.NotContains(src, `StatusTeapot`)
+	assert.Contains(src, `yadu.HandleList(*param_id, param_limit, *param_filter)`)
+	assert.Contains(src, `var param_limit *int`)
+
+	// an optional param is nil when its key is missing
+	url := serveTestProject(t, map[string]string{"items/items.go": `
+package items
+
+import "fmt"
+
+// @path("GET","/items")
+// @query("q","limit")
+func List(q *string, limit *int) string {
+	if limit == nil {
+		return fmt.Sprintf("q=%v limit=nil", *q)
+	}
+	return fmt.Sprintf("q=%v limit=%v", *q, *limit)
+}
+`})
+	for query, expected := range map[string]string{
+		"?q=x":          `"q=x limit=nil"`,
+		"?q=x&limit=10": `"q=x limit=10"`,
+	} {
+		status, body := get(t, url+"/items"+query)
+		assert.Equal(200, status, query)
+		assert.Equal(expected, strings.TrimSpace(body), query)
+	}
+}
+
+func TestParamsNamedAsTheLocalsOfTheGeneratedSrc(t *testing.T) {
+	assert := a.New(t)
+	url := serveTestProject(t, map[string]string{"clash/clash.go": `
+package clash
+
+import "fmt"
+
+// @path("GET","/clash/:id/:idS")
+// @query("p","r","err","json")
+func Clash(id, idS string, p, r *string, err *int, json *bool) string {
+	return fmt.Sprint(id, idS, *p, *r, *err, *json)
+}
+`})
+	status, body := get(t, url+"/clash/a/b?p=c&r=d&err=1&json=true")
+	assert.Equal(200, status)
+	assert.Equal(`"abcd1 true"`, strings.TrimSpace(body))
+}
+
+func TestParseParamWithNamedTypes(t *testing.T) {
+	assert := a.New(t)
+	expr, _ := parser.ParseExpr(`func(u User, m *models.Meta, xs []int){}`)
+	flit, ok := expr.(*ast.FuncLit)
+	if !assert.True(ok) {
+		return
+	}
+	params, err := matte.ParseParam(flit.Type.Params.List[0])
+	if assert.NoError(err) {
+		assert.Equal("User", params[0].Type)
+		assert.True(params[0].Required)
+	}
+	params, err = matte.ParseParam(flit.Type.Params.List[1])
+	if assert.NoError(err) {
+		assert.Equal("*models.Meta", params[0].Type)
+		assert.False(params[0].Required)
+	}
+	_, err = matte.ParseParam(flit.Type.Params.List[2])
+	assert.Error(err)
+}
+
+func TestGetParamsVerifierSrcWithBody(t *testing.T) {
+	assert := a.New(t)
+	s := matte.GetParamsVerifierSrc([]*matte.Param{
+		{
+			Name:     "id",
+			Type:     "int",
+			Required: true,
+			In:       matte.InPath,
+		},
+		{
+			Name:     "user",
+			Type:     "handlers.User",
+			Required: true,
+			In:       matte.InBody,
+			MaxBytes: 4096,
+		},
+	}, "handlers.UpdateUser")
+	sb, err := format.Source([]byte(s))
+	if !assert.NoError(err) {
+		return
+	}
+	src := string(sb)
+	assert.Contains(src, `mime.ParseMediaType(r.Header.Get("Content-Type"))`)
+	assert.Contains(src, `param_user := new(handlers.User)`)
+	assert.Contains(src, `http.MaxBytesReader(w, r.Body, 4096)`)
+	assert.Contains(src, `http.StatusRequestEntityTooLarge`)
+	assert.Contains(src, `handlers.UpdateUser(*param_id, *param_user)`)
+}
+
+func TestParseCommentWithQuery(t *testing.T) {
+	assert := a.New(t)
+	file, err := parser.ParseFile(token.NewFileSet(), "list.go", `
+package handlers
+
+// List lists the items
+// @path("GET","/items")
+// @query("limit","offset")
+func List(limit *int, offset *int) {}
+`, parser.ParseComments)
+	if !assert.NoError(err) {
+		return
+	}
+	fn := file.Decls[0].(*ast.FuncDecl)
+	decorators, err := matte.ParseComment(fn.Doc)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Contains(decorators, "path")
+	assert.Contains(decorators, "query")
+}
+
+func TestParamTypesOfPkgsHavingTheSameName(t *testing.T) {
+	assert := a.New(t)
+	url := serveTestProject(t, map[string]string{
+		"models/v2/models.go": `
+package models
+
+type ID string
+`,
+		"other/models/models.go": `
+package models
+
+type Tag string
+`,
+		"users/users.go": `
+package users
+
+import (
+	"fmt"
+
+	m "github.com/ondbyte/test/models/v2"
+	"github.com/ondbyte/test/other/models"
+)
+
+// @path("GET","/users/:id")
+// @query("tag")
+func Get(id m.ID, tag *models.Tag) string { return fmt.Sprint(id, *tag) }
+`,
+		// has the name of a pkg imported by the app
+		"json/json.go": `
+package json
+
+// @path("GET","/json")
+func Get() string { return "json" }
+`,
+	})
+	status, body := get(t, url+"/users/a?tag=b")
+	assert.Equal(200, status)
+	assert.Equal(`"ab"`, strings.TrimSpace(body))
+	status, body = get(t, url+"/json")
+	assert.Equal(200, status)
+	assert.Equal(`"json"`, strings.TrimSpace(body))
+}
@@ -0,0 +1,174 @@
+package matte
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeText = "text/plain"
+	ContentTypeHTML = "text/html"
+)
+
+// Result is a value returned by a handler
+type Result struct {
+	Name string
+	// type as written in the src of the handler
+	Type     string
+	typeExpr ast.Expr
+}
+
+const (
+	producesHint = `ex: @produces("text/plain")`
+	statusHint   = `status must be a number between 100 and 599, ex: @status(201)`
+)
+
+// import path of the runtime pkg used by the generated src
+const WebImportPath = "github.com/ondbyte/matte/web"
+
+// Response describes how the results of a handler are written into the http response
+type Response struct {
+	// results of the handler except the trailing error
+	Results     []*Result
+	ContentType string
+	// status code of a successful response
+	Status int
+	// whether the last result of the handler is an error, the status of
+	// a non nil error is decided by web.StatusOf
+	ReturnsError bool
+}
+
+// parses the results of the handler along with the 'produces' and 'status' decorators,
+// ex: @produces("text/plain") @status(201)
+func ParseResponse(decorators map[string]*Decorator, handler *ast.FuncDecl) (*Response, error) {
+	response := &Response{Results: []*Result{}}
+	if handler.Type.Results != nil {
+		for _, field := range handler.Type.Results.List {
+			resultType := types.ExprString(field.Type)
+			if len(field.Names) == 0 {
+				response.Results = append(response.Results, &Result{Type: resultType, typeExpr: field.Type})
+				continue
+			}
+			for _, name := range field.Names {
+				response.Results = append(response.Results, &Result{Name: name.Name, Type: resultType, typeExpr: field.Type})
+			}
+		}
+	}
+	if last := len(response.Results) - 1; last >= 0 && response.Results[last].Type == "error" {
+		response.ReturnsError = true
+		response.Results = response.Results[:last]
+	}
+	if len(response.Results) > 1 {
+		return nil, errorAt(handler.Type.Results.Pos(), "", "return a struct holding the values instead",
+			"a handler can return at most one value and an error, but %v returns %v values", handler.Name.Name, len(response.Results))
+	}
+
+	producesDecorator := decorators["produces"]
+	produces, err := producesDecorator.stringArgs()
+	if err != nil {
+		return nil, producesDecorator.errorf(producesHint, "invalid produces decorator: %v", err)
+	}
+	switch {
+	case len(produces) > 1:
+		return nil, producesDecorator.errorf(producesHint, "produces decorator must have a single content type")
+	case len(produces) == 1:
+		response.ContentType = produces[0]
+	case len(response.Results) == 1:
+		response.ContentType = contentTypeForResultName(response.Results[0].Name)
+	}
+
+	response.Status = http.StatusOK
+	if len(response.Results) == 0 {
+		response.Status = http.StatusNoContent
+	}
+	if statusDecorator := decorators["status"]; statusDecorator != nil {
+		if len(statusDecorator.args) != 1 {
+			return nil, statusDecorator.errorf(statusHint, "status decorator must have a single status code")
+		}
+		response.Status, err = strconv.Atoi(statusDecorator.args[0])
+		if err != nil || response.Status < 100 || response.Status > 599 {
+			return nil, statusDecorator.errorf(statusHint, "status decorator has invalid status code %v", statusDecorator.args[0])
+		}
+	}
+	return response, nil
+}
+
+// the name of a result hints its content type, ex: textResponse is text/plain, anything else is json
+func contentTypeForResultName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "text"):
+		return ContentTypeText
+	case strings.HasPrefix(name, "html"):
+		return ContentTypeHTML
+	}
+	return ContentTypeJSON
+}
+
+func isJSONContentType(contentType string) bool {
+	return contentType == ContentTypeJSON || strings.HasSuffix(contentType, "+json")
+}
+
+// requires the imports used by the src of GetResponseSrc
+func (m *Matte) requireResponseImports(response *Response) {
+	switch {
+	case len(response.Results) == 0:
+	case isJSONContentType(response.ContentType):
+		m.requireImport("encoding/json")
+	case response.Results[0].Type != "[]byte":
+		m.requireImport("fmt")
+	}
+}
+
+// returns the src which calls the handler using the call expression and writes its results into the response
+func GetResponseSrc(response *Response, call string) string {
+	errCheck := ""
+	if response.ReturnsError {
+		errCheck = `if err != nil {
+			web.WriteError(w, r, err)
+			return
+		}
+		`
+	}
+	if len(response.Results) == 0 {
+		if response.ReturnsError {
+			call = "err = " + call
+		}
+		return fmt.Sprintf(`%v
+		%vw.WriteHeader(%v)`, call, errCheck, response.Status)
+	}
+	s := fmt.Sprintf(`handlerResult := %v
+	`, call)
+	if response.ReturnsError {
+		s = fmt.Sprintf(`handlerResult, err := %v
+		%v`, call, errCheck)
+	}
+	switch {
+	case isJSONContentType(response.ContentType):
+		s += fmt.Sprintf(`resultBytes, err := json.Marshal(handlerResult)
+		if err != nil {
+			http.Error(w, "unable to encode the response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "%v")
+		w.WriteHeader(%v)
+		w.Write(resultBytes)`, response.ContentType, response.Status)
+	case response.Results[0].Type == "[]byte":
+		s += fmt.Sprintf(`w.Header().Set("Content-Type", "%v")
+		w.WriteHeader(%v)
+		w.Write(handlerResult)`, response.ContentType, response.Status)
+	default:
+		contentType := response.ContentType
+		if !strings.Contains(contentType, "charset") {
+			contentType += "; charset=utf-8"
+		}
+		s += fmt.Sprintf(`w.Header().Set("Content-Type", "%v")
+		w.WriteHeader(%v)
+		fmt.Fprint(w, handlerResult)`, contentType, response.Status)
+	}
+	return s
+}
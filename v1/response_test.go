@@ -0,0 +1,108 @@
+package matte_test
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestParseResponse(t *testing.T) {
+	assert := a.New(t)
+	file, err := parser.ParseFile(token.NewFileSet(), "response.go", `
+package handlers
+
+// @path("GET","/hello")
+func Hello() (jsonResponse string) { return "" }
+
+// @path("GET","/page")
+// @produces("text/html")
+func Page() string { return "" }
+
+// @path("POST","/things")
+// @status(201)
+func Create() (textResponse string) { return "" }
+
+// @path("DELETE","/things")
+func Delete() {}
+
+// @path("GET","/users/:id")
+func GetUser(id int) (*User, error) { return nil, nil }
+
+// @path("DELETE","/users/:id")
+func DeleteUser(id int) error { return nil }
+
+// @path("GET","/pair")
+func Pair() (int, int) { return 0, 0 }
+`, parser.ParseComments)
+	if !assert.NoError(err) {
+		return
+	}
+	expected := []struct {
+		contentType  string
+		status       int
+		results      int
+		returnsError bool
+	}{
+		{matte.ContentTypeJSON, 200, 1, false},
+		{matte.ContentTypeHTML, 200, 1, false},
+		{matte.ContentTypeText, 201, 1, false},
+		{"", 204, 0, false},
+		{matte.ContentTypeJSON, 200, 1, true},
+		{"", 204, 0, true},
+	}
+	for i, e := range expected {
+		fn := file.Decls[i].(*ast.FuncDecl)
+		decorators, err := matte.ParseComment(fn.Doc)
+		if !assert.NoError(err) {
+			return
+		}
+		response, err := matte.ParseResponse(decorators, fn)
+		if assert.NoError(err, fn.Name.Name) {
+			assert.Equal(e.contentType, response.ContentType, fn.Name.Name)
+			assert.Equal(e.status, response.Status, fn.Name.Name)
+			assert.Len(response.Results, e.results, fn.Name.Name)
+			assert.Equal(e.returnsError, response.ReturnsError, fn.Name.Name)
+		}
+	}
+	fn := file.Decls[6].(*ast.FuncDecl)
+	decorators, _ := matte.ParseComment(fn.Doc)
+	_, err = matte.ParseResponse(decorators, fn)
+	assert.Error(err)
+}
+
+func TestGetResponseSrc(t *testing.T) {
+	assert := a.New(t)
+	s := matte.GetResponseSrc(&matte.Response{
+		Results:     []*matte.Result{{Name: "textResponse", Type: "string"}},
+		ContentType: matte.ContentTypeText,
+		Status:      201,
+	}, "handlers.Hello()")
+	sb, err := format.Source([]byte("package main\nfunc f(){\n" + s + "\n}"))
+	if !assert.NoError(err) {
+		return
+	}
+	src := string(sb)
+	assert.Contains(src, `handlerResult := handlers.Hello()`)
+	assert.Contains(src, `w.Header().Set("Content-Type", "text/plain; charset=utf-8")`)
+	assert.Contains(src, `w.WriteHeader(201)`)
+	assert.Contains(src, `fmt.Fprint(w, handlerResult)`)
+
+	s = matte.GetResponseSrc(&matte.Response{
+		Results:      []*matte.Result{{Type: "*User"}},
+		ContentType:  matte.ContentTypeJSON,
+		Status:       200,
+		ReturnsError: true,
+	}, "handlers.GetUser(*id)")
+	sb, err = format.Source([]byte("package main\nfunc f(){\n" + s + "\n}"))
+	if !assert.NoError(err) {
+		return
+	}
+	src = string(sb)
+	assert.Contains(src, `handlerResult, err := handlers.GetUser(*id)`)
+	assert.Contains(src, `web.WriteError(w, r, err)`)
+}
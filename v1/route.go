@@ -0,0 +1,333 @@
+package matte
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/ondbyte/matte/swaggo"
+)
+
+// Route is a handler mounted on a method and path
+type Route struct {
+	Method string
+	Path   string
+	// name of the handler as called from the generated src, ex: handlers.Hello
+	Handler string
+	Pkg     *Pkg
+	// file declaring the handler
+	File *ast.File
+	// doc of the handler without the decorators
+	Doc string
+	// first line of the doc unless set by a summary decorator
+	Summary string
+	// tags grouping the route in the docs, the name of the pkg unless set by a tags decorator
+	Tags       []string
+	Deprecated bool
+	// security schemes of the route and their scopes, each element is an alternative
+	Security []map[string][]string
+	Params   []*Param
+	Response *Response
+	// position of the path decorator of the handler
+	Pos token.Pos
+}
+
+const docHint = `ex: @summary("creates a user"), @description("..."), @tags("users","admin"), @security("ApiKeyAuth || OAuth2[read, write]") or @deprecated()`
+
+// sets the docs of the route from the summary, description, tags and deprecated decorators
+func applyDocDecorators(route *Route, decorators map[string]*Decorator) error {
+	route.Summary, _, _ = strings.Cut(route.Doc, "\n")
+	route.Tags = []string{route.Pkg.Name}
+	for _, name := range []string{"summary", "description"} {
+		decorator := decorators[name]
+		args, err := decorator.stringArgs()
+		if err != nil || (decorator != nil && len(args) != 1) {
+			return decorator.errorf(docHint, "%v decorator must have a single string arg", name)
+		}
+		if len(args) == 1 && name == "summary" {
+			route.Summary = args[0]
+		} else if len(args) == 1 {
+			route.Doc = args[0]
+		}
+	}
+	tagsDecorator := decorators["tags"]
+	tags, err := tagsDecorator.stringArgs()
+	if err != nil {
+		return tagsDecorator.errorf(docHint, "invalid tags decorator: %v", err)
+	}
+	if len(tags) > 0 {
+		route.Tags = tags
+	}
+	securityDecorator := decorators["security"]
+	security, err := securityDecorator.stringArgs()
+	if err != nil {
+		return securityDecorator.errorf(docHint, "invalid security decorator: %v", err)
+	}
+	for _, requirement := range security {
+		route.Security = append(route.Security, swaggo.ParseSecurity(requirement))
+	}
+	if deprecatedDecorator := decorators["deprecated"]; deprecatedDecorator != nil {
+		if len(deprecatedDecorator.args) != 0 {
+			return deprecatedDecorator.errorf(docHint, "deprecated decorator takes no args")
+		}
+		route.Deprecated = true
+	}
+	return nil
+}
+
+// returns the conflict between the paths of a and b when registered for the same method,
+// it mirrors the rules of httprouter which panics at startup on such a conflict
+func pathConflict(a, b string) string {
+	if a == b {
+		return "duplicate"
+	}
+	aSegments, bSegments := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(aSegments) && i < len(bSegments); i++ {
+		aSegment, bSegment := aSegments[i], bSegments[i]
+		aWild, bWild := isWildcard(aSegment), isWildcard(bSegment)
+		switch {
+		case !aWild && !bWild:
+			if aSegment != bSegment {
+				return ""
+			}
+		case aWild && bWild:
+			if aSegment != bSegment {
+				return fmt.Sprintf("wildcard '%v' conflicts with wildcard '%v'", bSegment, aSegment)
+			}
+		case aWild:
+			return fmt.Sprintf("segment '%v' conflicts with wildcard '%v'", bSegment, aSegment)
+		default:
+			return fmt.Sprintf("wildcard '%v' conflicts with segment '%v'", bSegment, aSegment)
+		}
+		if strings.HasPrefix(aSegment, "*") {
+			// a catch-all takes the rest of the path
+			return fmt.Sprintf("catch-all '%v' conflicts with the rest of the path", aSegment)
+		}
+	}
+	return ""
+}
+
+func isWildcard(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+// adds the route to the route table of m, unless it conflicts with a route already added
+func (m *Matte) addRoute(route *Route) error {
+	for _, existing := range m.Routes {
+		if existing.Method != route.Method {
+			continue
+		}
+		conflict := pathConflict(existing.Path, route.Path)
+		if conflict == "" {
+			continue
+		}
+		existingPosition := m.fileSet.Position(existing.Pos)
+		if conflict == "duplicate" {
+			return errorAt(route.Pos, "", "change the method or path of one of the handlers",
+				"path %v is already registered for %v by handler %v at %v, so cannot register it with handler %v again",
+				route.Path, route.Method, existing.Handler, existingPosition, route.Handler)
+		}
+		return errorAt(route.Pos, "", "httprouter cannot tell these paths apart, make them differ before the wildcard",
+			"path %v of handler %v conflicts with path %v of handler %v at %v: %v",
+			route.Path, route.Handler, existing.Path, existing.Handler, existingPosition, conflict)
+	}
+	m.Routes = append(m.Routes, route)
+	return nil
+}
+
+// returns the name of the client function calling each route, the name of its handler prefixed with its pkg if
+// handlers of different pkgs have the same name, and suffixed with the method and path of the route if its handler
+// has several routes, ex: UsersListGetV2Users
+func (m *Matte) clientNames() map[*Route]string {
+	pkgs := map[string]map[string]bool{}
+	routes := map[string]int{}
+	for _, route := range m.Routes {
+		pkgName, name, _ := strings.Cut(route.Handler, ".")
+		if pkgs[name] == nil {
+			pkgs[name] = map[string]bool{}
+		}
+		pkgs[name][pkgName] = true
+		routes[route.Handler]++
+	}
+	names := map[*Route]string{}
+	taken := map[string]bool{}
+	for _, route := range m.Routes {
+		pkgName, name, _ := strings.Cut(route.Handler, ".")
+		if len(pkgs[name]) > 1 {
+			name = strings.ToUpper(pkgName[:1]) + pkgName[1:] + name
+		}
+		if routes[route.Handler] > 1 {
+			name += goIdent(strings.ToLower(route.Method)+route.Path, true)
+		}
+		unique := name
+		for i := 2; taken[unique]; i++ {
+			unique = name + strconv.Itoa(i)
+		}
+		taken[unique] = true
+		names[route] = unique
+	}
+	return names
+}
+
+// returns the names of the wildcards of the path, ex: "/users/:id/*rest" has id and rest
+func pathWildcards(path string) (names []string, catchAll string) {
+	for _, segment := range strings.Split(path, "/") {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			names = append(names, segment[1:])
+		case strings.HasPrefix(segment, "*"):
+			names = append(names, segment[1:])
+			catchAll = segment[1:]
+		}
+	}
+	return names, catchAll
+}
+
+// verifies every wildcard of the path has a param and every param read from the path has a wildcard,
+// params read from the path or query must be of a type which can be parsed from a string
+func (m *Matte) checkPathParams(pathDecorator *Decorator, path string, params []*Param, caller string) error {
+	diagnostics := Diagnostics{}
+	names, catchAll := pathWildcards(path)
+	for _, name := range names {
+		if name == "" {
+			diagnostics.Add(pathDecorator.errorf("name the wildcard, ex: /users/:id", "path %v has a wildcard without a name", path))
+			continue
+		}
+		param := findParam(params, name)
+		if param == nil {
+			diagnostics.Add(pathDecorator.errorf(fmt.Sprintf("add a param '%v' to %v", name, caller),
+				"path %v has wildcard '%v' but %v has no param named '%v'", path, name, caller, name))
+			continue
+		}
+		if param.location() != InPath {
+			diagnostics.Add(errorAt(param.pos, "", fmt.Sprintf("remove '%v' from the %v decorator", name, param.location()),
+				"param '%v' of %v is a wildcard of path %v but is read from the %v", name, caller, path, param.location()))
+		}
+	}
+	for _, param := range params {
+		switch param.location() {
+		case InPath:
+			if !contains(names, param.Name) {
+				diagnostics.Add(errorAt(param.pos, "",
+					fmt.Sprintf(`add :%v to the path, or read it from the query using @query("%v")`, param.Name, param.Name),
+					"param '%v' of %v is not a wildcard of path %v", param.Name, caller, path))
+				continue
+			}
+			if !param.Required {
+				diagnostics.Add(errorAt(param.pos, "", "path wildcards always have a value, use a non pointer type",
+					"path param '%v' of %v cannot be optional", param.Name, caller))
+				continue
+			}
+		case InQuery:
+		default:
+			continue
+		}
+		param.kind = m.scalarKind(strings.TrimPrefix(param.Type, "*"))
+		if param.Name == catchAll && param.location() == InPath && param.kind != "string" {
+			diagnostics.Add(errorAt(param.pos, "", "a catch-all has the rest of the path, use a string",
+				"catch-all param '%v' of %v must be a string but is %v", param.Name, caller, param.Type))
+			continue
+		}
+		if param.kind == "" {
+			diagnostics.Add(errorAt(param.pos, "", "use a string, bool or number type, or read it from the body using @body",
+				"%v param '%v' of %v is of type %v which cannot be parsed from a string", param.location(), param.Name, caller, param.Type))
+		}
+	}
+	return diagnostics.Err()
+}
+
+func contains(xs []string, x string) bool {
+	for _, y := range xs {
+		if x == y {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the string, bool or number type the type, as written in the current file, is or is defined as,
+// ex: string for type ID string, empty if it is none of them
+func (m *Matte) scalarKind(typeName string) string {
+	switch typeName {
+	case "string", "bool", "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "byte", "rune":
+		return typeName
+	}
+	typeSpec, _, _ := m.lookupType(m.currentPkg, m.currentFile, typeName)
+	if typeSpec == nil {
+		return ""
+	}
+	underlying, ok := typeSpec.Type.(*ast.Ident)
+	if !ok || underlying.Name == typeSpec.Name.Name {
+		return ""
+	}
+	return m.scalarKind(underlying.Name)
+}
+
+// finds the declaration of the type as written in the file of the pkg, ex: "User" or "models.User",
+// along with the pkg and file declaring it, returns nil if the type is not declared in the project
+func (m *Matte) lookupType(pkg *Pkg, file *ast.File, typeName string) (*ast.TypeSpec, *Pkg, *ast.File) {
+	pkgName, name, isSelector := strings.Cut(typeName, ".")
+	if isSelector {
+		pkg = m.pkgForImportName(file, pkgName)
+	} else {
+		name = typeName
+	}
+	if pkg == nil {
+		return nil, nil, nil
+	}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec := spec.(*ast.TypeSpec); typeSpec.Name.Name == name {
+					if typeSpec.Doc == nil && !genDecl.Lparen.IsValid() {
+						// the doc of a type declared without parens belongs to its decl
+						typeSpec.Doc = genDecl.Doc
+					}
+					return typeSpec, pkg, file
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// returns the project pkg imported with the name in the file
+func (m *Matte) pkgForImportName(file *ast.File, importName string) *Pkg {
+	for _, i := range file.Imports {
+		importPath, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+		if i.Name != nil && i.Name.Name != importName {
+			continue
+		}
+		for _, pkg := range m.Pkgs {
+			if pkg.ImportPath == importPath && (i.Name != nil || pkg.Name == importName) {
+				return pkg
+			}
+		}
+	}
+	return nil
+}
+
+// returns the text of the doc without the lines having decorators
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	lines := []string{}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,163 @@
+package matte_test
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	"github.com/stretchr/testify/assert"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestPathParamsMustMatchHandlerParams(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"users/users.go": `
+package users
+
+type ID int
+
+type Filter struct{}
+
+// @path("GET","/users/:id/posts/:postID")
+func Posts(id ID) string { return "" }
+
+// @path("GET","/users/:id")
+func Get(id int, verbose bool) string { return "" }
+
+// @path("GET","/users/:id/avatar")
+func Avatar(id *int) string { return "" }
+
+// @path("GET","/files/*file")
+func File(file int) string { return "" }
+
+// @path("GET","/search")
+// @query("filter")
+func Search(filter Filter) string { return "" }
+
+// @path("GET","/users/:id/friends")
+// @query("limit")
+func Friends(id ID, limit *int) string { return "" }
+`})
+	err := matte.Build(token.NewFileSet(), dir)
+	var diagnostics matte.Diagnostics
+	if !assert.ErrorAs(err, &diagnostics) {
+		return
+	}
+	msgs := []string{}
+	for _, d := range diagnostics {
+		msgs = append(msgs, d.Msg)
+	}
+	assert.Equal([]string{
+		"path /users/:id/posts/:postID has wildcard 'postID' but users.Posts has no param named 'postID'",
+		"param 'verbose' of users.Get is not a wildcard of path /users/:id",
+		"path param 'id' of users.Avatar cannot be optional",
+		"catch-all param 'file' of users.File must be a string but is int",
+		"query param 'filter' of users.Search is of type Filter which cannot be parsed from a string",
+	}, msgs)
+}
+
+func TestWithHttpFrameworkHavingDuplicatePath(t *testing.T) {
+	for name, decorator := range map[string]string{
+		"path decorator":  `// @path("GET","/hello")`,
+		"swag annotation": `// @Router /hello [get]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			errorSrc := fmt.Sprintf(`
+package corehttp
+
+%v
+func HandlePost() string {
+	return "ondbyte"
+}
+
+%v
+func HandleGet() string {
+	return "ondbyte"
+}
+`, decorator, decorator)
+			assert := assert.New(t)
+			dir := newTestProject(t, map[string]string{"corehttp/corehttp.go": errorSrc})
+			err := matte.Build(token.NewFileSet(), dir)
+			expectedErr := fmt.Sprintf(`%v:9:4: path /hello is already registered for GET by handler corehttp.HandlePost at %v:4:4, so cannot register it with handler corehttp.HandleGet again`,
+				filepath.Join(dir, "corehttp", "corehttp.go"), filepath.Join(dir, "corehttp", "corehttp.go"))
+			if assert.Error(err, "expected a error") {
+				assert.Equal(expectedErr, strings.Split(err.Error(), "\n")[0], "expected error :%v", expectedErr)
+			}
+		})
+	}
+}
+
+func TestWithHttpFrameworkHavingConflictingWildcards(t *testing.T) {
+	assert := assert.New(t)
+	dir := newTestProject(t, map[string]string{"users/users.go": `
+package users
+
+// @path("GET","/users/:id")
+func Get(id int) string { return "" }
+
+// @path("GET","/users/new")
+func New() string { return "" }
+
+// @path("GET","/users/:name/posts")
+func Posts(name string) string { return "" }
+
+// @path("POST","/users/new")
+func Create() {}
+
+// @path("GET","/users/:id/friends")
+func Friends(id int) string { return "" }
+`})
+	err := matte.Build(token.NewFileSet(), dir)
+	var diagnostics matte.Diagnostics
+	if !assert.ErrorAs(err, &diagnostics) {
+		return
+	}
+	if assert.Len(diagnostics, 2) {
+		assert.Contains(diagnostics[0].Msg, "path /users/new of handler users.New conflicts with path /users/:id of handler users.Get")
+		assert.Contains(diagnostics[1].Msg, "path /users/:name/posts of handler users.Posts conflicts with path /users/:id of handler users.Get")
+	}
+}
+
+func TestNamedStringParams(t *testing.T) {
+	assert := assert.New(t)
+	url := serveTestProject(t, map[string]string{"users/users.go": `
+package users
+
+import "fmt"
+
+type ID string
+
+type Role string
+
+type Page int
+
+type Rest string
+
+// @path("GET","/files/*rest")
+func File(rest Rest) string { return string(rest) }
+
+// @path("GET","/users/:id")
+// @query("role","page")
+func Get(id ID, role *Role, page Page) string {
+	if role == nil {
+		return fmt.Sprintf("%v nil %v", id, page)
+	}
+	return fmt.Sprintf("%v %v %v", id, *role, page)
+}
+`})
+	for path, expected := range map[string]string{
+		"/users/abc?page=2":            `"abc nil 2"`,
+		"/users/abc?role=admin&page=2": `"abc admin 2"`,
+		"/files/a/b":                   `"/a/b"`,
+	} {
+		status, body := get(t, url+path)
+		assert.Equal(200, status, path)
+		assert.Equal(expected, strings.TrimSpace(body), path)
+	}
+	status, body := get(t, url+"/users/abc?page=two")
+	assert.Equal(400, status)
+	assert.Contains(body, "value 'two' cannot be parsed as users.Page")
+}
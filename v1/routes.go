@@ -0,0 +1,104 @@
+package matte
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteFilter selects routes, an empty field matches every route
+type RouteFilter struct {
+	// method of the route, case insensitive
+	Method string
+	// leading segments of the path of the route, ex: /users matches /users and /users/:id
+	PathPrefix string
+	// name or import path of the pkg of the handler
+	Pkg string
+}
+
+func (f RouteFilter) match(route *Route) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, route.Method) {
+		return false
+	}
+	// the prefix matches whole segments, so /users does not match /usersettings
+	prefix := strings.TrimSuffix(f.PathPrefix, "/")
+	if prefix != "" && route.Path != prefix && !strings.HasPrefix(route.Path, prefix+"/") {
+		return false
+	}
+	return f.Pkg == "" || f.Pkg == route.Pkg.Name || f.Pkg == route.Pkg.ImportPath
+}
+
+// RouteInfo describes a route of the project as listed by ListRoutes
+type RouteInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// ex: users.Get
+	Handler string      `json:"handler"`
+	Params  []ParamInfo `json:"params"`
+	// file:line:col of the path decorator of the handler, relative to the project
+	Position string `json:"position"`
+}
+
+// ParamInfo describes a param of a handler
+type ParamInfo struct {
+	Name     string        `json:"name"`
+	In       ParamLocation `json:"in"`
+	Type     string        `json:"type"`
+	Required bool          `json:"required"`
+}
+
+// ListRoutes processes the project without writing anything and returns its routes selected by the filter,
+// sorted by path and method
+func ListRoutes(fileSet *token.FileSet, project string, filter RouteFilter) ([]RouteInfo, error) {
+	m, err := Load(fileSet, project)
+	if err != nil {
+		return nil, err
+	}
+	routes := []RouteInfo{}
+	for _, route := range m.Routes {
+		if !filter.match(route) {
+			continue
+		}
+		position := fileSet.Position(route.Pos)
+		if rel, err := filepath.Rel(project, position.Filename); err == nil {
+			position.Filename = rel
+		}
+		info := RouteInfo{Method: route.Method, Path: route.Path, Handler: route.Handler, Params: []ParamInfo{}, Position: position.String()}
+		for _, param := range route.Params {
+			info.Params = append(info.Params, ParamInfo{Name: param.Name, In: param.location(), Type: param.Type, Required: param.Required})
+		}
+		routes = append(routes, info)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
+
+// WriteRoutesTable writes the routes as a table, a param is written as in:name, with a '?' when it is optional
+func WriteRoutesTable(w io.Writer, routes []RouteInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tPARAMS\tPOSITION")
+	for _, route := range routes {
+		params := []string{}
+		for _, param := range route.Params {
+			s := fmt.Sprintf("%v:%v", param.In, param.Name)
+			if !param.Required {
+				s += "?"
+			}
+			params = append(params, s)
+		}
+		if len(params) == 0 {
+			params = append(params, "-")
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", route.Method, route.Path, route.Handler, strings.Join(params, " "), route.Position)
+	}
+	return tw.Flush()
+}
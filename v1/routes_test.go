@@ -0,0 +1,95 @@
+package matte_test
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestListRoutes(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+// @path("GET","/users/:id")
+// @query("verbose")
+func Get(id int, verbose *bool) string { return "" }
+
+// @path("DELETE","/users/:id")
+func Delete(id int) {}
+`,
+		"orders/orders.go": `
+package orders
+
+// @path("GET","/orders")
+func List() []string { return nil }
+`,
+	})
+	routes, err := matte.ListRoutes(token.NewFileSet(), dir, matte.RouteFilter{})
+	if !assert.NoError(err) {
+		return
+	}
+	var table strings.Builder
+	assert.NoError(matte.WriteRoutesTable(&table, routes))
+	assert.Equal(`METHOD  PATH        HANDLER       PARAMS                  POSITION
+GET     /orders     orders.List   -                       orders/orders.go:4:4
+DELETE  /users/:id  users.Delete  path:id                 users/users.go:8:4
+GET     /users/:id  users.Get     path:id query:verbose?  users/users.go:4:4
+`, table.String())
+
+	routes, err = matte.ListRoutes(token.NewFileSet(), dir, matte.RouteFilter{Method: "get", PathPrefix: "/users"})
+	if assert.NoError(err) && assert.Len(routes, 1) {
+		assert.Equal("users.Get", routes[0].Handler)
+		assert.Equal([]matte.ParamInfo{
+			{Name: "id", In: matte.InPath, Type: "int", Required: true},
+			{Name: "verbose", In: matte.InQuery, Type: "*bool"},
+		}, routes[0].Params)
+	}
+	routes, err = matte.ListRoutes(token.NewFileSet(), dir, matte.RouteFilter{Pkg: "github.com/ondbyte/test/orders"})
+	if assert.NoError(err) && assert.Len(routes, 1) {
+		assert.Equal("/orders", routes[0].Path)
+	}
+}
+
+func TestListRoutesByPathPrefix(t *testing.T) {
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+// @path("GET","/users")
+func List() []string { return nil }
+
+// @path("GET","/users/:id")
+func Get(id int) string { return "" }
+
+// @path("GET","/usersettings")
+func Settings() string { return "" }
+`,
+	})
+	for _, c := range []struct {
+		prefix string
+		paths  []string
+	}{
+		{"/users", []string{"/users", "/users/:id"}},
+		{"/users/", []string{"/users", "/users/:id"}},
+		{"/users/:id", []string{"/users/:id"}},
+		{"/user", nil},
+		{"/", []string{"/users", "/users/:id", "/usersettings"}},
+	} {
+		t.Run(c.prefix, func(t *testing.T) {
+			routes, err := matte.ListRoutes(token.NewFileSet(), dir, matte.RouteFilter{PathPrefix: c.prefix})
+			if !a.NoError(t, err) {
+				return
+			}
+			var paths []string
+			for _, route := range routes {
+				paths = append(paths, route.Path)
+			}
+			a.Equal(t, c.paths, paths)
+		})
+	}
+}
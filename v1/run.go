@@ -0,0 +1,261 @@
+package matte
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ondbyte/matte/web"
+)
+
+// RunOptions configures Run
+type RunOptions struct {
+	// options of go build, the binary is built into a temp dir unless Output is set
+	BuildOptions
+	// how often the project is checked for changes, 500ms if zero
+	Interval time.Duration
+	// how long the app is given to exit after an interrupt before it is killed, 5s if zero
+	StopTimeout time.Duration
+}
+
+// Run builds and starts the app of the project, then rebuilds and restarts it on every change of the
+// go files of the project until ctx is done, a change which does not build leaves the running app as is
+func Run(ctx context.Context, project string, options RunOptions) error {
+	if options.Interval <= 0 {
+		options.Interval = 500 * time.Millisecond
+	}
+	if options.StopTimeout <= 0 {
+		options.StopTimeout = 5 * time.Second
+	}
+	if options.Stdout == nil {
+		options.Stdout = io.Discard
+	}
+	if options.Stderr == nil {
+		options.Stderr = io.Discard
+	}
+	if options.Output == "" {
+		tempDir, err := os.MkdirTemp("", "matte-run")
+		if err != nil {
+			return fmt.Errorf("unable to make a temp dir for the binary due to err: %v", err)
+		}
+		defer os.RemoveAll(tempDir)
+		options.Output = filepath.Join(tempDir, "app")
+		if runtime.GOOS == "windows" {
+			options.Output += ".exe"
+		}
+	}
+
+	// the output dir and the excluded dirs are known once the config is loaded, the snapshot is taken before
+	// the first build so a change made while it builds is not missed
+	isSkippedDir := func(string) bool { return false }
+	snapshot := projectFiles(project, isSkippedDir)
+	// the app is run for development unless MATTE_ENV says otherwise, so it serves its docs
+	env := os.Getenv(web.EnvVar)
+	if env == "" {
+		env = "development"
+	}
+	// the app is built next to the running binary, which is replaced once the app is stopped
+	buildOptions := options.BuildOptions
+	buildOptions.Output = options.Output + ".next"
+	var routes []*Route
+	var app *runningApp
+	defer func() {
+		app.stop(options.StopTimeout)
+	}()
+	reload := func() {
+		m, err := generate(token.NewFileSet(), project, env)
+		if err == nil {
+			err = m.compile(buildOptions)
+		}
+		if err != nil {
+			fmt.Fprintln(options.Stderr, err)
+			if app != nil {
+				fmt.Fprintln(options.Stderr, "matte: the app is left running as it was, fix the errors to reload it")
+			}
+			return
+		}
+		if app != nil {
+			added, removed := DiffRoutes(routes, m.Routes)
+			fmt.Fprintf(options.Stderr, "matte: reloading, %v\n", routeChangesSummary(added, removed))
+			for _, route := range removed {
+				fmt.Fprintf(options.Stderr, "  - %v %v %v\n", route.Method, route.Path, route.Handler)
+			}
+			for _, route := range added {
+				fmt.Fprintf(options.Stderr, "  + %v %v %v\n", route.Method, route.Path, route.Handler)
+			}
+			app.stop(options.StopTimeout)
+		}
+		routes = m.Routes
+		isSkippedDir = m.isSkippedDir
+		// files of the dirs skipped by the config just loaded are no more watched
+		for filePath := range snapshot {
+			if isSkippedDir(filepath.Dir(filePath)) {
+				delete(snapshot, filePath)
+			}
+		}
+		err = os.Rename(buildOptions.Output, options.Output)
+		if err != nil {
+			fmt.Fprintf(options.Stderr, "matte: unable to replace the binary of the app due to err: %v\n", err)
+			return
+		}
+		app, err = startApp(project, options.Output, env, options.Stdout, options.Stderr)
+		if err != nil {
+			fmt.Fprintln(options.Stderr, err)
+		}
+	}
+	reload()
+
+	ticker := time.NewTicker(options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		current := projectFiles(project, isSkippedDir)
+		if changed := changedFiles(snapshot, current); len(changed) > 0 {
+			snapshot = current
+			fmt.Fprintf(options.Stderr, "matte: %v changed\n", strings.Join(changed, ", "))
+			reload()
+		}
+	}
+}
+
+// DiffRoutes returns the routes of after which are not in before and the ones of before which are not in after,
+// a route is the same if its method, path and handler are
+func DiffRoutes(before, after []*Route) (added, removed []*Route) {
+	key := func(route *Route) string {
+		return route.Method + " " + route.Path + " " + route.Handler
+	}
+	beforeKeys, afterKeys := map[string]bool{}, map[string]bool{}
+	for _, route := range before {
+		beforeKeys[key(route)] = true
+	}
+	for _, route := range after {
+		afterKeys[key(route)] = true
+		if !beforeKeys[key(route)] {
+			added = append(added, route)
+		}
+	}
+	for _, route := range before {
+		if !afterKeys[key(route)] {
+			removed = append(removed, route)
+		}
+	}
+	return added, removed
+}
+
+func routeChangesSummary(added, removed []*Route) string {
+	if len(added) == 0 && len(removed) == 0 {
+		return "routes unchanged"
+	}
+	return fmt.Sprintf("%v routes added, %v removed", len(added), len(removed))
+}
+
+// a started binary of the app
+type runningApp struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	// set once matte stops the app, so its exit is not reported
+	stopped atomic.Bool
+}
+
+// starts the binary of the app for the env it is built for
+func startApp(project, binary, env string, stdout, stderr io.Writer) (*runningApp, error) {
+	cmd := exec.Command(binary)
+	cmd.Dir = project
+	cmd.Env = append(os.Environ(), web.EnvVar+"="+env)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err := cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the app due to err: %v", err)
+	}
+	app := &runningApp{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		if err != nil && !app.stopped.Load() {
+			fmt.Fprintf(stderr, "matte: app exited: %v\n", err)
+		}
+		close(app.done)
+	}()
+	return app, nil
+}
+
+// interrupts the app so it can shut down gracefully, it is killed if it does not exit within the timeout
+func (app *runningApp) stop(timeout time.Duration) {
+	if app == nil {
+		return
+	}
+	select {
+	case <-app.done:
+		return
+	default:
+	}
+	app.stopped.Store(true)
+	// interrupts cannot be sent on windows
+	if runtime.GOOS == "windows" || app.cmd.Process.Signal(os.Interrupt) != nil {
+		app.cmd.Process.Kill()
+	}
+	select {
+	case <-app.done:
+	case <-time.After(timeout):
+		app.cmd.Process.Kill()
+		<-app.done
+	}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// returns the go files of the project along with its go.mod and matte.toml, walking the same tree as loadProject
+func projectFiles(project string, isSkippedDir func(dir string) bool) map[string]fileStamp {
+	files := map[string]fileStamp{}
+	filepath.WalkDir(project, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if filePath != project && (strings.HasSuffix(d.Name(), MatteDir) || isSkippedDir(filePath)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		isGoFile := strings.HasSuffix(filePath, ".go") && !strings.HasSuffix(filePath, "_test.go")
+		if !isGoFile && d.Name() != "go.mod" && filePath != filepath.Join(project, ConfigTOMLFile) {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files[filePath] = fileStamp{info.ModTime(), info.Size()}
+		}
+		return nil
+	})
+	return files
+}
+
+// returns the files which are added, removed or modified in after
+func changedFiles(before, after map[string]fileStamp) []string {
+	changed := []string{}
+	for _, filePath := range sortedKeys(after) {
+		if stamp, ok := before[filePath]; !ok || stamp != after[filePath] {
+			changed = append(changed, filePath)
+		}
+	}
+	for _, filePath := range sortedKeys(before) {
+		if _, ok := after[filePath]; !ok {
+			changed = append(changed, filePath)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,83 @@
+package matte_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondbyte/matte/v1"
+	"github.com/ondbyte/matte/web"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestDiffRoutes(t *testing.T) {
+	assert := a.New(t)
+	get := &matte.Route{Method: "GET", Path: "/users/:id", Handler: "users.Get"}
+	list := &matte.Route{Method: "GET", Path: "/users", Handler: "users.List"}
+	create := &matte.Route{Method: "POST", Path: "/users", Handler: "users.Create"}
+	moved := &matte.Route{Method: "GET", Path: "/people", Handler: "users.List"}
+	added, removed := matte.DiffRoutes([]*matte.Route{get, list}, []*matte.Route{get, create, moved})
+	assert.Equal([]*matte.Route{create, moved}, added)
+	assert.Equal([]*matte.Route{list}, removed)
+	added, removed = matte.DiffRoutes([]*matte.Route{get}, []*matte.Route{get})
+	assert.Empty(added)
+	assert.Empty(removed)
+}
+
+func TestRunReloadsTheApp(t *testing.T) {
+	assert := a.New(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	handler := `package hello
+
+// @path("GET","/hello")
+func Hello() string { return %q }
+`
+	// the app is run for development unless MATTE_ENV says otherwise
+	t.Setenv(web.EnvVar, "")
+	dir := newCompilableTestProject(t, map[string]string{
+		matte.ConfigTOMLFile: fmt.Sprintf("addr = %q\n\n[env.production]\naddr = \"127.0.0.1:1\"\n", addr),
+		"hello/hello.go":     fmt.Sprintf(handler, "v1"),
+	})
+	output := filepath.Join(t.TempDir(), "app")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- matte.Run(ctx, dir, matte.RunOptions{BuildOptions: matte.BuildOptions{Output: output}, Interval: 50 * time.Millisecond})
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(<-done)
+	}()
+	// reports whether /hello responds with the body in time, the app is unreachable while it restarts
+	waitFor := func(body string) bool {
+		for start := time.Now(); time.Since(start) < 30*time.Second; time.Sleep(50 * time.Millisecond) {
+			resp, err := http.Get("http://" + addr + "/hello")
+			if err != nil {
+				continue
+			}
+			got, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(got) == body {
+				return true
+			}
+		}
+		return false
+	}
+	if !assert.True(waitFor(`"v1"`), "the app is not started") {
+		return
+	}
+	writeFiles(t, dir, map[string]string{"hello/hello.go": fmt.Sprintf(handler, "v2")})
+	assert.True(waitFor(`"v2"`), "the app is not reloaded")
+	assert.FileExists(output)
+	assert.NoFileExists(output + ".next")
+}
@@ -0,0 +1,214 @@
+package matte
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// template of the scaffold which runs every server, the others are servers
+const scaffoldMain = "main.go"
+
+const (
+	importsPlaceholder       = "importsPlaceHolder"
+	serverRunnersPlaceholder = "serverRunnersPlaceHolder"
+)
+
+// name of the func starting the server of a template
+var serverRunnerName = regexp.MustCompile(`^Run\w+Server$`)
+
+// a `var _ = "xPlaceHolder"` of a template, at the byte offsets start:end of its src, its doc included,
+// one in a func is replaced with statements, any other with declarations
+type placeholder struct {
+	name       string
+	start, end int
+	inFunc     bool
+}
+
+// a template of the scaffold, parsed
+type scaffoldFile struct {
+	name string
+	src  []byte
+	file *ast.File
+	// offset of the first declaration after the imports
+	bodyStart    int
+	placeholders []placeholder
+}
+
+// renders the app from the templates of the scaffold pkg, every placeholder is replaced with its src, the
+// server runners placeholder with the RunXServer funcs of the servers, and the imports placeholder with nothing,
+// as the imports are merged into the imports of the app, an import is a spec, ex: "log" or _ "embed"
+func renderScaffold(files fs.FS, imports []string, placeholders map[string]string) ([]byte, error) {
+	templates, err := parseScaffold(files)
+	if err != nil {
+		return nil, err
+	}
+	runners := ""
+	for _, template := range templates {
+		if template.name == scaffoldMain {
+			continue
+		}
+		for _, decl := range template.file.Decls {
+			fnDecl, ok := decl.(*ast.FuncDecl)
+			if ok && fnDecl.Recv == nil && serverRunnerName.MatchString(fnDecl.Name.Name) &&
+				fnDecl.Type.Params.NumFields() == 0 && fnDecl.Type.Results.NumFields() == 2 {
+				runners += fmt.Sprintf("ServerRunners = append(ServerRunners, %v)\n", fnDecl.Name.Name)
+			}
+		}
+	}
+	replacements := map[string]string{importsPlaceholder: "", serverRunnersPlaceholder: runners}
+	for name, src := range placeholders {
+		replacements[name] = src
+	}
+
+	specs, seen, bodies := []string{}, map[string]bool{}, []string{}
+	addSpec := func(spec string) {
+		if !seen[spec] {
+			seen[spec] = true
+			specs = append(specs, spec)
+		}
+	}
+	used := map[string]bool{}
+	for _, template := range templates {
+		for _, spec := range template.file.Imports {
+			addSpec(string(template.src[template.offset(spec.Pos()):template.offset(spec.End())]))
+		}
+		src := template.src
+		// replaced from the last one, so the offsets of the others hold
+		for i := len(template.placeholders) - 1; i >= 0; i-- {
+			p := template.placeholders[i]
+			replacement, ok := replacements[p.name]
+			if !ok {
+				return nil, fmt.Errorf("placeholder %v of scaffold %v has no src to be replaced with", p.name, template.name)
+			}
+			used[p.name] = true
+			if err := p.check(replacement); err != nil {
+				return nil, fmt.Errorf("src of placeholder %v of scaffold %v is invalid: %v", p.name, template.name, err)
+			}
+			src = append(append(append([]byte{}, src[:p.start]...), replacement...), src[p.end:]...)
+		}
+		bodies = append(bodies, string(src[template.bodyStart:]))
+	}
+	for name := range replacements {
+		if !used[name] {
+			return nil, fmt.Errorf("scaffold has no placeholder %v", name)
+		}
+	}
+	for _, spec := range imports {
+		addSpec(spec)
+	}
+
+	// the std lib is imported apart from the rest, as gofmt would have it
+	std, others := []string{}, []string{}
+	for _, spec := range specs {
+		importPath, _ := strconv.Unquote(spec[strings.Index(spec, `"`):])
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	app := []byte(fmt.Sprintf("package main\n\nimport (\n%v\n\n%v\n)\n\n%v",
+		strings.Join(std, "\n"), strings.Join(others, "\n"), strings.Join(bodies, "\n")))
+	formatted, err := format.Source(app)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the app rendered from the scaffold due to err: %v", err)
+	}
+	return formatted, nil
+}
+
+// returns the syntax error of the src replacing the placeholder, its position is the one in the src
+func (p placeholder) check(src string) error {
+	wrapped := "package scaffold\n\n//line " + p.name + ":1:1\n" + src + "\n"
+	if p.inFunc {
+		wrapped = "package scaffold\n\nfunc _() {\n//line " + p.name + ":1:1\n" + src + "\n}\n"
+	}
+	_, err := parser.ParseFile(token.NewFileSet(), "", wrapped, parser.SkipObjectResolution)
+	return err
+}
+
+// parses every template of the scaffold, main.go first
+func parseScaffold(files fs.FS) ([]*scaffoldFile, error) {
+	names, err := fs.Glob(files, "*.go")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the scaffold due to err: %v", err)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return names[i] == scaffoldMain && names[j] != scaffoldMain
+	})
+	fileSet := token.NewFileSet()
+	templates := []*scaffoldFile{}
+	for _, name := range names {
+		src, err := fs.ReadFile(files, name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read scaffold %v due to err: %v", name, err)
+		}
+		file, err := parser.ParseFile(fileSet, name, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse scaffold %v due to err: %v", name, err)
+		}
+		template := &scaffoldFile{name: name, src: src, file: file}
+		template.bodyStart = template.offset(file.Name.End())
+		for _, decl := range file.Decls {
+			if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+				template.bodyStart = template.offset(genDecl.End())
+			}
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			var genDecl *ast.GenDecl
+			inFunc := false
+			switch node := node.(type) {
+			case *ast.GenDecl:
+				genDecl = node
+			case *ast.DeclStmt:
+				genDecl, _ = node.Decl.(*ast.GenDecl)
+				inFunc = true
+			}
+			if name := placeholderName(genDecl); name != "" {
+				start := genDecl.Pos()
+				if genDecl.Doc != nil {
+					start = genDecl.Doc.Pos()
+				}
+				template.placeholders = append(template.placeholders, placeholder{
+					name: name, start: template.offset(start), end: template.offset(genDecl.End()), inFunc: inFunc,
+				})
+				return false
+			}
+			return true
+		})
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// returns the name of the placeholder declared by the decl, empty if it is not a `var _ = "xPlaceHolder"`
+func placeholderName(genDecl *ast.GenDecl) string {
+	if genDecl == nil || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+		return ""
+	}
+	spec, ok := genDecl.Specs[0].(*ast.ValueSpec)
+	if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "_" || len(spec.Values) != 1 {
+		return ""
+	}
+	lit, ok := spec.Values[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	name, err := strconv.Unquote(lit.Value)
+	if err != nil || !strings.HasSuffix(name, "PlaceHolder") {
+		return ""
+	}
+	return name
+}
+
+// returns the offset of the pos in the src of the template
+func (s *scaffoldFile) offset(pos token.Pos) int {
+	return int(pos - s.file.FileStart)
+}
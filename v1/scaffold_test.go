@@ -0,0 +1,87 @@
+package matte_test
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildRendersTheScaffold(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"pages/pages.go": `
+package pages
+
+// @path("GET","/pages/:name")
+func Page(name string) (htmlResponse string) { return "" }
+`})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	app := readApp(t, dir)
+	assert.True(strings.HasPrefix(app, "package main\n"))
+	assert.NotContains(app, "PlaceHolder")
+	assert.Contains(app, "ServerRunners = append(ServerRunners, RunHTTPServer)")
+	assert.Contains(app, "func RunHTTPServer() (chan error, ShutDowner) {")
+	assert.Contains(app, `router.Handle("GET", "/pages/:name", func(`)
+	// the default addr is DefaultPort, so it is not assigned again
+	assert.Contains(app, `addr := DefaultPort`)
+	assert.NotContains(app, `addr = `)
+	assert.Contains(app, "return errChan, server.Shutdown")
+}
+
+func TestRenderScaffold(t *testing.T) {
+	files := fstest.MapFS{"main.go": {Data: []byte(`package scaffold
+
+var _ = "importsPlaceHolder"
+
+func main() {
+	var _ = "serverRunnersPlaceHolder"
+	// replaced with the greeting
+	var _ = "greetingPlaceHolder"
+}
+`)}}
+	for _, c := range []struct {
+		name         string
+		placeholders map[string]string
+		app, err     string
+	}{
+		{
+			name:         "rendered",
+			placeholders: map[string]string{"greetingPlaceHolder": `log.Println("hello")`},
+			app:          "package main\n\nimport (\n\t\"log\"\n)\n\nfunc main() {\n\n\tlog.Println(\"hello\")\n}\n",
+		},
+		{
+			name:         "placeholder without src",
+			placeholders: map[string]string{},
+			err:          "placeholder greetingPlaceHolder of scaffold main.go has no src to be replaced with",
+		},
+		{
+			name:         "src without placeholder",
+			placeholders: map[string]string{"greetingPlaceHolder": "", "farewellPlaceHolder": ""},
+			err:          "scaffold has no placeholder farewellPlaceHolder",
+		},
+		{
+			name:         "invalid src",
+			placeholders: map[string]string{"greetingPlaceHolder": "log.Println(\"hello\"\n"},
+			err:          "src of placeholder greetingPlaceHolder of scaffold main.go is invalid: greetingPlaceHolder:1:20: missing ','",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			app, err := matte.RenderScaffold(files, []string{`"log"`}, c.placeholders)
+			if c.err != "" {
+				if a.Error(t, err) {
+					a.Contains(t, err.Error(), c.err)
+				}
+				return
+			}
+			if a.NoError(t, err) {
+				a.Equal(t, c.app, string(app))
+			}
+		})
+	}
+}
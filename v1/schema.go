@@ -0,0 +1,149 @@
+package matte
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// generates openapi schemas of go types declared in the project,
+// named struct types become components which are referenced
+type schemaGen struct {
+	m          *Matte
+	components openapi3.Schemas
+}
+
+func newSchemaGen(m *Matte) *schemaGen {
+	return &schemaGen{m: m, components: openapi3.Schemas{}}
+}
+
+// returns the schema of the type expr as written in the file of the pkg
+func (g *schemaGen) schemaOf(expr ast.Expr, pkg *Pkg, file *ast.File) *openapi3.SchemaRef {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if schema := builtinSchema(expr.Name); schema != nil {
+			return openapi3.NewSchemaRef("", schema)
+		}
+		return g.namedSchema(expr.Name, pkg, file)
+	case *ast.SelectorExpr:
+		name := types.ExprString(expr)
+		if name == "time.Time" {
+			return openapi3.NewSchemaRef("", openapi3.NewDateTimeSchema())
+		}
+		return g.namedSchema(name, pkg, file)
+	case *ast.StarExpr:
+		return g.schemaOf(expr.X, pkg, file)
+	case *ast.ArrayType:
+		if ident, ok := expr.Elt.(*ast.Ident); ok && ident.Name == "byte" && expr.Len == nil {
+			return openapi3.NewSchemaRef("", openapi3.NewBytesSchema())
+		}
+		schema := openapi3.NewArraySchema()
+		schema.Items = g.schemaOf(expr.Elt, pkg, file)
+		return openapi3.NewSchemaRef("", schema)
+	case *ast.MapType:
+		schema := openapi3.NewObjectSchema()
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: g.schemaOf(expr.Value, pkg, file)}
+		return openapi3.NewSchemaRef("", schema)
+	case *ast.StructType:
+		return openapi3.NewSchemaRef("", g.structSchema(expr, pkg, file))
+	}
+	// interfaces, funcs and chans can be anything
+	return openapi3.NewSchemaRef("", openapi3.NewSchema())
+}
+
+// returns the schema of a named type, structs are added to the components and referenced
+func (g *schemaGen) namedSchema(name string, pkg *Pkg, file *ast.File) *openapi3.SchemaRef {
+	typeSpec, typePkg, typeFile := g.m.lookupType(pkg, file, name)
+	if typeSpec == nil {
+		// declared outside the project
+		return openapi3.NewSchemaRef("", openapi3.NewSchema())
+	}
+	structType, ok := typeSpec.Type.(*ast.StructType)
+	if !ok {
+		return g.schemaOf(typeSpec.Type, typePkg, typeFile)
+	}
+	componentName := typePkg.Name + "." + typeSpec.Name.Name
+	ref := "#/components/schemas/" + componentName
+	if _, ok := g.components[componentName]; !ok {
+		// added before its fields so recursive types end up referencing it
+		componentRef := openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+		g.components[componentName] = componentRef
+		componentRef.Value = g.structSchema(structType, typePkg, typeFile)
+		componentRef.Value.Title = typeSpec.Name.Name
+		if typeSpec.Doc != nil {
+			componentRef.Value.Description = strings.TrimSpace(typeSpec.Doc.Text())
+		}
+	}
+	return openapi3.NewSchemaRef(ref, g.components[componentName].Value)
+}
+
+// returns the object schema of the struct, fields are named by their json tags as encoding/json does
+func (g *schemaGen) structSchema(structType *ast.StructType, pkg *Pkg, file *ast.File) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	for _, field := range structType.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			tag, _ = strconv.Unquote(field.Tag.Value)
+		}
+		jsonName, jsonOpts, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+		if jsonName == "-" && jsonOpts == "" {
+			continue
+		}
+		if len(field.Names) == 0 {
+			// embedded fields are flattened into the struct
+			embedded := g.schemaOf(field.Type, pkg, file).Value
+			if jsonName == "" && embedded != nil && embedded.Type == openapi3.TypeObject {
+				for name, property := range embedded.Properties {
+					schema.Properties[name] = property
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+			}
+			continue
+		}
+		_, isPointer := field.Type.(*ast.StarExpr)
+		omitEmpty := strings.Contains(jsonOpts, "omitempty")
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propertyName := name.Name
+			if jsonName != "" {
+				propertyName = jsonName
+			}
+			property := g.schemaOf(field.Type, pkg, file)
+			if field.Doc != nil && property.Ref == "" {
+				property.Value.Description = strings.TrimSpace(field.Doc.Text())
+			}
+			schema.Properties[propertyName] = property
+			if !isPointer && !omitEmpty {
+				schema.Required = append(schema.Required, propertyName)
+			}
+		}
+	}
+	return schema
+}
+
+// returns the schema of a builtin type, nil if name is not a builtin type
+func builtinSchema(name string) *openapi3.Schema {
+	switch name {
+	case "string", "error":
+		return openapi3.NewStringSchema()
+	case "bool":
+		return openapi3.NewBoolSchema()
+	case "int", "int64", "uint", "uint64":
+		return openapi3.NewInt64Schema()
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32", "byte", "rune":
+		return openapi3.NewInt32Schema()
+	case "float32":
+		return openapi3.NewFloat64Schema().WithFormat("float")
+	case "float64":
+		return openapi3.NewFloat64Schema().WithFormat("double")
+	case "any":
+		return openapi3.NewSchema()
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package matte
+
+import (
+	"go/ast"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ondbyte/matte/swaggo"
+	"github.com/swaggo/swag"
+)
+
+// swag annotated handlers are supported by translating their annotations into the decorators of matte,
+// so they are routed, validated and documented exactly like the decorated ones
+
+const swagHint = `swag annotations look like @Router /users/{id} [get], see https://github.com/swaggo/swag#api-operation`
+
+// returns the swag attribute of a comment line if it has one, ex: @Router of "// @Router /hello [get]"
+func swagAttribute(text string) (attribute string, ok bool) {
+	line := strings.TrimSpace(strings.TrimLeft(text, "/"))
+	if !strings.HasPrefix(line, "@") {
+		return "", false
+	}
+	attribute = swaggo.FieldsByAnySpace(line, 2)[0]
+	return attribute, swaggo.IsAttribute(attribute)
+}
+
+// matte reads the types of the params and the results from the handler itself, so go types named in a
+// swag annotation are replaced by 'object' letting swag parse the annotation without loading the packages
+func untypedSwagComment(attribute, text string) string {
+	line := strings.TrimSpace(strings.TrimLeft(text, "/"))
+	// both @Param name in type required "description" and @Success 200 {object} type "description"
+	// have the type as their 4th field
+	const typeField = 3
+	fields := swaggo.FieldsByAnySpace(line, typeField+2)
+	if len(fields) <= typeField {
+		return line
+	}
+	switch strings.ToLower(attribute) {
+	case "@param":
+	case "@success", "@failure", "@response":
+		if !strings.HasPrefix(fields[2], "{") {
+			// an empty response, ex: @Success 204 "no content"
+			return line
+		}
+	default:
+		return line
+	}
+	typeName := strings.TrimPrefix(fields[typeField], "[]")
+	if !swag.IsPrimitiveType(swag.TransToValidSchemeType(typeName)) {
+		fields[typeField] = strings.TrimSuffix(fields[typeField], typeName) + swag.OBJECT
+	}
+	return strings.Join(fields, " ")
+}
+
+// converts the path of a swag @Router to the one of httprouter, ex: /users/{id} to /users/:id
+func toRouterPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// parses the swag annotations of the doc of a handler using the swaggo pkg, and returns the decorators
+// equivalent to them, one decorators map for each @Router of the handler
+func (m *Matte) swagDecorators(doc *ast.CommentGroup) (routes []map[string]*Decorator, err error) {
+	operation := swag.NewOperation(nil)
+	markDownFileDir := filepath.Dir(m.fileSet.Position(m.currentFile.Pos()).Filename)
+	// decorators point at the annotation they were translated from
+	newDecorator := func(name string, c *ast.Comment, args ...string) *Decorator {
+		return &Decorator{
+			name:    name,
+			args:    args,
+			pos:     c.Slash + token.Pos(strings.Index(c.Text, "@")),
+			comment: c.Text,
+		}
+	}
+	routers := []*ast.Comment{}
+	// comment of each param, in the order they were annotated
+	params := []*ast.Comment{}
+	// comment of each response status
+	responses := map[int]*ast.Comment{}
+	var produces, summary, description, tags, deprecated *ast.Comment
+	// each @Security annotation is an alternative security requirement of the handler
+	security := []*ast.Comment{}
+	for _, c := range doc.List {
+		attribute, ok := swagAttribute(c.Text)
+		if !ok {
+			continue
+		}
+		paramsCount := len(operation.Parameters)
+		err = swaggo.ParseComment(operation, untypedSwagComment(attribute, c.Text), m.currentFile, markDownFileDir)
+		if err != nil {
+			return nil, errorAt(newDecorator("", c).pos, c.Text, swagHint, "invalid swag annotation %v: %v", attribute, err)
+		}
+		switch strings.ToLower(attribute) {
+		case "@router":
+			routers = append(routers, c)
+		case "@param":
+			for i := paramsCount; i < len(operation.Parameters); i++ {
+				params = append(params, c)
+			}
+		case "@success", "@failure", "@response":
+			for code := range operation.Responses.StatusCodeResponses {
+				if responses[code] == nil {
+					responses[code] = c
+				}
+			}
+		case "@produce":
+			produces = c
+		case "@summary":
+			summary = c
+		case "@description", "@description.markdown":
+			description = c
+		case "@tags":
+			tags = c
+		case "@deprecated":
+			deprecated = c
+		case "@security":
+			security = append(security, c)
+		}
+	}
+	if len(routers) == 0 {
+		// not a handler
+		return nil, nil
+	}
+
+	decorators := map[string]*Decorator{}
+	queryNames := []string{}
+	var queryComment *ast.Comment
+	for i, param := range operation.Parameters {
+		switch param.In {
+		case "path":
+			// params are read from the path by default
+		case "query":
+			queryNames = append(queryNames, strconv.Quote(param.Name))
+			if queryComment == nil {
+				queryComment = params[i]
+			}
+		case "body":
+			if decorators["body"] != nil {
+				return nil, errorAt(newDecorator("", params[i]).pos, params[i].Text, swagHint,
+					"only a single body param can be annotated, but found %v and %v", decorators["body"].args[0], strconv.Quote(param.Name))
+			}
+			decorators["body"] = newDecorator("body", params[i], strconv.Quote(param.Name))
+		default:
+			return nil, errorAt(newDecorator("", params[i]).pos, params[i].Text, "params can be read only from the path, query or body",
+				"param '%v' of location '%v' is not supported", param.Name, param.In)
+		}
+	}
+	if len(queryNames) > 0 {
+		decorators["query"] = newDecorator("query", queryComment, queryNames...)
+	}
+	if produces != nil && len(operation.Produces) > 0 {
+		decorators["produces"] = newDecorator("produces", produces, strconv.Quote(operation.Produces[0]))
+	}
+	// the lowest success status is the status of the handler
+	codes := []int{}
+	for code := range responses {
+		if code >= http.StatusOK && code < http.StatusMultipleChoices {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	if len(codes) > 0 {
+		decorators["status"] = newDecorator("status", responses[codes[0]], strconv.Itoa(codes[0]))
+	}
+	if summary != nil {
+		decorators["summary"] = newDecorator("summary", summary, strconv.Quote(operation.Summary))
+	}
+	if description != nil {
+		decorators["description"] = newDecorator("description", description, strconv.Quote(operation.Description))
+	}
+	if tags != nil {
+		tagArgs := []string{}
+		for _, tag := range operation.Tags {
+			tagArgs = append(tagArgs, strconv.Quote(tag))
+		}
+		decorators["tags"] = newDecorator("tags", tags, tagArgs...)
+	}
+	if len(security) > 0 {
+		requirements := []string{}
+		for _, c := range security {
+			fields := swaggo.FieldsByAnySpace(strings.TrimSpace(strings.TrimLeft(c.Text, "/")), 2)
+			if len(fields) == 2 {
+				requirements = append(requirements, strconv.Quote(fields[1]))
+			}
+		}
+		decorators["security"] = newDecorator("security", security[0], requirements...)
+	}
+	if deprecated != nil {
+		decorators["deprecated"] = newDecorator("deprecated", deprecated)
+	}
+
+	for i, router := range operation.RouterProperties {
+		route := map[string]*Decorator{}
+		for name, decorator := range decorators {
+			route[name] = decorator
+		}
+		route["path"] = newDecorator("path", routers[i],
+			strconv.Quote(strings.ToUpper(router.HTTPMethod)), strconv.Quote(toRouterPath(router.Path)))
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// processes a handler annotated with swag, decorators of matte in the same doc take precedence over the annotations
+func (m *Matte) processSwagFn(routes []map[string]*Decorator, decorators map[string]*Decorator, fnDecl *ast.FuncDecl) error {
+	if pathDecorator := decorators["path"]; pathDecorator != nil {
+		return pathDecorator.errorf("remove either the path decorator or the @Router annotation",
+			"%v has both a path decorator and a swag @Router annotation", fnDecl.Name.Name)
+	}
+	diagnostics := Diagnostics{}
+	for _, route := range routes {
+		for name, decorator := range decorators {
+			route[name] = decorator
+		}
+		if err := m.ProcessPath(route, fnDecl); err != nil {
+			diagnostics.Add(m.resolve(err))
+		}
+	}
+	return diagnostics.Err()
+}
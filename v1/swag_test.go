@@ -0,0 +1,83 @@
+package matte_test
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildWithSwagAnnotations(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+type User struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+// GetUser godoc
+// @Summary      Get a user
+// @Description  get the user having the id
+// @Tags         users
+// @Produce      json
+// @Param        id      path   int     true   "id of the user"
+// @Param        fields  query  string  false  "fields to return"
+// @Success      200  {object}  users.User
+// @Failure      404  {object}  web.Problem
+// @Router       /users/{id} [get]
+func GetUser(id int, fields *string) (*User, error) { return nil, nil }
+
+// @Summary  Create a user
+// @Param    user  body  users.User  true  "the user"
+// @Success  201  {object}  users.User
+// @Router   /users [post]
+// @Deprecated
+func CreateUser(user User) (*User, error) { return nil, nil }
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	doc, err := openapi3.NewLoader().LoadFromFile(filepath.Join(dir, matte.MatteDir, matte.OpenAPIJSONFile))
+	if !assert.NoError(err) {
+		return
+	}
+	getUser := doc.Paths.Find("/users/{id}").Get
+	if assert.NotNil(getUser) {
+		assert.Equal("Get a user", getUser.Summary)
+		assert.Equal("get the user having the id", getUser.Description)
+		assert.Equal([]string{"users"}, getUser.Tags)
+		if assert.Len(getUser.Parameters, 2) {
+			assert.Equal("path", getUser.Parameters[0].Value.In)
+			assert.Equal("query", getUser.Parameters[1].Value.In)
+			assert.False(getUser.Parameters[1].Value.Required)
+		}
+	}
+	createUser := doc.Paths.Find("/users").Post
+	if assert.NotNil(createUser) {
+		assert.True(createUser.Deprecated)
+		assert.NotNil(createUser.RequestBody)
+		assert.NotNil(createUser.Responses.Status(201))
+	}
+}
+
+func TestSwagAnnotationErrorHasPosition(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{"users/users.go": `
+package users
+
+// @Param    token  header  string  true  "auth token"
+// @Router   /users [get]
+func List(token string) string { return "" }
+`})
+	err := matte.Build(token.NewFileSet(), dir)
+	if assert.Error(err) {
+		assert.Contains(err.Error(), filepath.Join(dir, "users", "users.go")+":4:4: param 'token' of location 'header' is not supported")
+	}
+}
@@ -0,0 +1,359 @@
+package matte
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// file of the generated typescript client inside the matte dir
+const ClientTSFile = "client.ts"
+
+// names declared by the typescript client itself, and the reserved words of js, which cannot name a function or param
+var tsReserved = []string{
+	"Client", "Problem", "InvalidParam", "ProblemError", "client", "send", "escapeCatchAll", "query", "response",
+	"break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do", "else", "enum",
+	"export", "extends", "false", "finally", "for", "function", "if", "import", "in", "instanceof", "new", "null",
+	"return", "super", "switch", "this", "throw", "true", "try", "typeof", "var", "void", "while", "with", "yield",
+	"let", "static", "implements", "interface", "package", "private", "protected", "public", "await", "async",
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// runtime of the typescript client, it depends on nothing but fetch
+const tsRuntime = `/** an invalid param of the request */
+export interface InvalidParam {
+  name: string;
+  in: string;
+  reason: string;
+}
+
+/** RFC 7807 problem details responded by the app for an error */
+export interface Problem {
+  type?: string;
+  title: string;
+  status: number;
+  detail?: string;
+  instance?: string;
+  "invalid-params"?: InvalidParam[];
+}
+
+/** thrown for every response not having a 2xx status */
+export class ProblemError extends Error {
+  readonly problem: Problem;
+
+  constructor(problem: Problem) {
+    super(problem.detail ? problem.status + " " + problem.title + ": " + problem.detail : problem.status + " " + problem.title);
+    this.name = "ProblemError";
+    this.problem = problem;
+  }
+}
+
+/** the app called by the functions of this module */
+export interface Client {
+  /** url the app is served at, ex: http://localhost:8000 */
+  baseURL: string;
+  /** sent with every request, ex: an Authorization header */
+  headers?: Record<string, string>;
+  /** aborts the requests */
+  signal?: AbortSignal;
+}
+
+async function send(client: Client, method: string, path: string, query: Record<string, string> | undefined, body?: unknown): Promise<Response> {
+  let url = client.baseURL.replace(/\/$/, "") + path;
+  if (query && Object.keys(query).length > 0) {
+    url += "?" + new URLSearchParams(query).toString();
+  }
+  const headers: Record<string, string> = { ...client.headers };
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const response = await fetch(url, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+    signal: client.signal,
+  });
+  if (response.ok) {
+    return response;
+  }
+  const text = await response.text();
+  if ((response.headers.get("Content-Type") ?? "").startsWith("application/problem+json")) {
+    try {
+      throw new ProblemError(JSON.parse(text) as Problem);
+    } catch (err) {
+      if (err instanceof ProblemError) {
+        throw err;
+      }
+    }
+  }
+  throw new ProblemError({ title: response.statusText, status: response.status, detail: text.trim() || undefined });
+}
+`
+
+// only added when a route has a catch-all, so the module has no unused function
+const tsEscapeCatchAll = `
+/** escapes the value of a catch-all param segment by segment, the leading '/' is left out as the path has it */
+function escapeCatchAll(value: string): string {
+  return value.replace(/^\//, "").split("/").map(encodeURIComponent).join("/");
+}
+`
+
+type tsGen struct {
+	g *schemaGen
+	// name of the typescript interface of every component
+	names map[string]string
+}
+
+// TypeScriptClientSrc returns the src of a typescript module having an interface for every struct used by the routes
+// and a function calling every route, it depends on nothing but fetch
+func (m *Matte) TypeScriptClientSrc() string {
+	t := &tsGen{g: newSchemaGen(m), names: map[string]string{}}
+	for _, route := range m.Routes {
+		// walking the routes adds every component they use, so each can be named before any is referenced
+		t.g.operation(route)
+	}
+	t.nameComponents()
+	names := m.clientNames()
+	functions := ""
+	for _, route := range m.Routes {
+		name := strings.ToLower(names[route][:1]) + names[route][1:]
+		if contains(tsReserved, name) {
+			// a reserved word is told apart by the pkg of the handler
+			pkgName, _, _ := strings.Cut(route.Handler, ".")
+			name = pkgName + names[route]
+		}
+		functions += t.function(name, route)
+	}
+	runtime := tsRuntime
+	if strings.Contains(functions, "escapeCatchAll(") {
+		runtime += tsEscapeCatchAll
+	}
+	return fmt.Sprintf(`// Code generated by matte. DO NOT EDIT.
+// client of %v, every handler of the app is a function of this module
+
+%v%v%v`, m.apiTitle(), runtime, t.interfaces(), functions)
+}
+
+// names the interface of every component, components having the same name in different pkgs are told apart by their pkg
+func (t *tsGen) nameComponents() {
+	componentNames := sortedKeys(t.g.components)
+	count := map[string]int{}
+	for _, componentName := range componentNames {
+		_, name, _ := strings.Cut(componentName, ".")
+		count[name]++
+	}
+	for _, componentName := range componentNames {
+		pkgName, name, _ := strings.Cut(componentName, ".")
+		if count[name] > 1 || contains(tsReserved, name) {
+			name = strings.ToUpper(pkgName[:1]) + pkgName[1:] + name
+		}
+		t.names[componentName] = name
+	}
+}
+
+// returns the src of the interfaces of the components
+func (t *tsGen) interfaces() string {
+	s := ""
+	for _, componentName := range sortedKeys(t.g.components) {
+		schema := t.g.components[componentName].Value
+		s += "\n" + tsDoc("", schema.Description, false)
+		s += fmt.Sprintf("export interface %v %v\n", t.names[componentName], t.objectType(schema, ""))
+	}
+	return s
+}
+
+// returns the jsdoc of the text, indented by indent
+func tsDoc(indent, text string, deprecated bool) string {
+	lines := []string{}
+	if text != "" {
+		lines = strings.Split(strings.ReplaceAll(text, "*/", "* /"), "\n")
+	}
+	if deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	switch len(lines) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%v/** %v */\n", indent, lines[0])
+	}
+	s := indent + "/**\n"
+	for _, line := range lines {
+		s += strings.TrimRight(indent+" * "+line, " ") + "\n"
+	}
+	return s + indent + " */\n"
+}
+
+// returns the typescript type of the schema
+func (t *tsGen) typeOf(ref *openapi3.SchemaRef, indent string) string {
+	if ref == nil || ref.Value == nil {
+		return "unknown"
+	}
+	if ref.Ref != "" {
+		return t.names[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]
+	}
+	schema := ref.Value
+	switch schema.Type {
+	case openapi3.TypeString:
+		return "string"
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		return "number"
+	case openapi3.TypeBoolean:
+		return "boolean"
+	case openapi3.TypeArray:
+		items := t.typeOf(schema.Items, indent)
+		if strings.ContainsAny(items, " |") {
+			items = "(" + items + ")"
+		}
+		return items + "[]"
+	case openapi3.TypeObject:
+		if schema.AdditionalProperties.Schema != nil {
+			return "Record<string, " + t.typeOf(schema.AdditionalProperties.Schema, indent) + ">"
+		}
+		return t.objectType(schema, indent)
+	}
+	return "unknown"
+}
+
+// returns the typescript type literal of the object schema, properties which are not required are optional
+func (t *tsGen) objectType(schema *openapi3.Schema, indent string) string {
+	s := "{\n"
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+		if property.Value != nil && property.Ref == "" {
+			s += tsDoc(indent+"  ", property.Value.Description, false)
+		}
+		optional := "?"
+		if contains(schema.Required, name) {
+			optional = ""
+		}
+		if !tsIdentifier.MatchString(name) {
+			name = fmt.Sprintf("%q", name)
+		}
+		s += fmt.Sprintf("%v  %v%v: %v;\n", indent, name, optional, t.typeOf(property, indent+"  "))
+	}
+	return s + indent + "}"
+}
+
+// returns the src of the function calling the route
+func (t *tsGen) function(name string, route *Route) string {
+	args := []string{"client: Client"}
+	argNames := map[*Param]string{}
+	lastRequired := -1
+	for i, param := range route.Params {
+		if param.Required {
+			lastRequired = i
+		}
+	}
+	for i, param := range route.Params {
+		argName := param.Name
+		if contains(tsReserved, argName) {
+			argName += "Param"
+		}
+		argNames[param] = argName
+		paramType := t.typeOf(t.g.schemaOf(param.typeExpr, route.Pkg, route.File), "")
+		switch {
+		case param.Required:
+			args = append(args, argName+": "+paramType)
+		case i > lastRequired:
+			args = append(args, argName+"?: "+paramType)
+		default:
+			// an optional param followed by a required one cannot be left out
+			args = append(args, argName+": "+paramType+" | undefined")
+		}
+	}
+
+	doc := route.Doc
+	if doc != "" {
+		doc += "\n\n"
+	}
+	doc += fmt.Sprintf("calls %v %v", route.Method, route.Path)
+
+	body := ""
+	query := "undefined"
+	for _, param := range route.Params {
+		if param.location() != InQuery {
+			continue
+		}
+		if query == "undefined" {
+			body += "  const query: Record<string, string> = {};\n"
+			query = "query"
+		}
+		value := tsParamValue(param, argNames[param])
+		if param.Required {
+			body += fmt.Sprintf("  query[%q] = %v;\n", param.Name, value)
+			continue
+		}
+		body += fmt.Sprintf("  if (%v !== undefined) {\n    query[%q] = %v;\n  }\n", argNames[param], param.Name, value)
+	}
+	requestBody := ""
+	for _, param := range route.Params {
+		if param.location() == InBody {
+			requestBody = ", " + argNames[param]
+		}
+	}
+	call := fmt.Sprintf("await send(client, %q, %v, %v%v)", route.Method, tsPathSrc(route, argNames), query, requestBody)
+
+	returnType, returnSrc := "void", fmt.Sprintf("  %v;\n", call)
+	response := route.Response
+	if len(response.Results) == 1 {
+		result := response.Results[0]
+		switch {
+		case isJSONContentType(response.ContentType):
+			returnType = t.typeOf(t.g.schemaOf(result.typeExpr, route.Pkg, route.File), "")
+			returnSrc = fmt.Sprintf("  const response = %v;\n  return (await response.json()) as %v;\n", call, returnType)
+		case result.Type == "[]byte":
+			returnType = "Uint8Array"
+			returnSrc = fmt.Sprintf("  const response = %v;\n  return new Uint8Array(await response.arrayBuffer());\n", call)
+		default:
+			returnType = "string"
+			returnSrc = fmt.Sprintf("  const response = %v;\n  return response.text();\n", call)
+		}
+	}
+	return fmt.Sprintf("\n%vexport async function %v(%v): Promise<%v> {\n%v%v}\n",
+		tsDoc("", doc, route.Deprecated), name, strings.Join(args, ", "), returnType, body, returnSrc)
+}
+
+// returns the src formatting the value of a path or query param the way the app parses it,
+// a param of type string as is and anything else as json
+func tsParamValue(param *Param, argName string) string {
+	if param.scalarKind() == "string" {
+		return argName
+	}
+	return "JSON.stringify(" + argName + ")"
+}
+
+// returns the src of the path of the route having its wildcards replaced by the args
+func tsPathSrc(route *Route, argNames map[*Param]string) string {
+	s := ""
+	for i, segment := range strings.Split(route.Path, "/") {
+		if i > 0 {
+			s += "/"
+		}
+		if !isWildcard(segment) {
+			s += strings.ReplaceAll(strings.ReplaceAll(segment, "`", "\\`"), "${", "\\${")
+			continue
+		}
+		param := findParam(route.Params, segment[1:])
+		if strings.HasPrefix(segment, "*") {
+			s += fmt.Sprintf("${escapeCatchAll(%v)}", argNames[param])
+			continue
+		}
+		s += fmt.Sprintf("${encodeURIComponent(%v)}", tsParamValue(param, argNames[param]))
+	}
+	return "`" + s + "`"
+}
+
+// writes the typescript client into the matte dir
+func (m *Matte) writeTypeScriptClient() error {
+	err := os.WriteFile(filepath.Join(m.matteDir, ClientTSFile), []byte(m.TypeScriptClientSrc()), 0666)
+	if err != nil {
+		return fmt.Errorf("failed to write file %v due to err: %v", ClientTSFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package matte_test
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondbyte/matte/v1"
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestBuildWritesTypeScriptClient(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+import "github.com/ondbyte/test/models"
+
+// @path("GET","/users/:id")
+// @query("fields","limit")
+func Get(id int, fields *string, limit int) (*models.User, error) { return nil, nil }
+
+// @path("DELETE","/users/:id")
+func Delete(id string) error { return nil }
+`,
+		"models/models.go": `
+package models
+
+// User of the app
+type User struct {
+	Name    string            ` + "`json:\"name\"`" + `
+	Emails  []string          ` + "`json:\"emails,omitempty\"`" + `
+	Manager *User             ` + "`json:\"manager-id\"`" + `
+	Labels  map[string]string ` + "`json:\"labels\"`" + `
+}
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	src, err := os.ReadFile(filepath.Join(dir, matte.MatteDir, matte.ClientTSFile))
+	if !assert.NoError(err) {
+		return
+	}
+	client := string(src)
+	for _, s := range []string{
+		`/** User of the app */
+export interface User {
+  emails?: string[];
+  labels: Record<string, string>;
+  "manager-id"?: User;
+  name: string;
+}`,
+		`export async function get(client: Client, id: number, fields: string | undefined, limit: number): Promise<User> {
+  const query: Record<string, string> = {};
+  if (fields !== undefined) {
+    query["fields"] = fields;
+  }
+  query["limit"] = JSON.stringify(limit);
+  const response = await send(client, "GET", ` + "`/users/${encodeURIComponent(JSON.stringify(id))}`" + `, query);
+  return (await response.json()) as User;
+}`,
+		`export async function usersDelete(client: Client, id: string): Promise<void> {`,
+	} {
+		assert.Contains(client, s)
+	}
+	assert.NotContains(client, "escapeCatchAll")
+}
+
+func TestTypeScriptFunctionsOfAHandlerHavingSeveralRoutes(t *testing.T) {
+	assert := a.New(t)
+	dir := newTestProject(t, map[string]string{
+		"users/users.go": `
+package users
+
+// @Param   q  query  string  false  "name to search"
+// @Router  /users [get]
+// @Router  /v2/users/all [get]
+func List(q *string) []string { return nil }
+`,
+		"orders/orders.go": `
+package orders
+
+// @path("GET","/orders")
+func List() []string { return nil }
+`,
+	})
+	err := matte.Build(token.NewFileSet(), dir)
+	if !assert.NoError(err) {
+		return
+	}
+	src, err := os.ReadFile(filepath.Join(dir, matte.MatteDir, matte.ClientTSFile))
+	if !assert.NoError(err) {
+		return
+	}
+	client := string(src)
+	assert.Contains(client, "export async function usersListGetUsers(client: Client, q?: string): Promise<string[]> {")
+	assert.Contains(client, "export async function usersListGetV2UsersAll(client: Client, q?: string): Promise<string[]> {")
+	assert.Contains(client, "export async function ordersList(client: Client): Promise<string[]> {")
+}
@@ -0,0 +1,138 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Client sends the requests of the clients generated by matte, the generated client embeds it
+type Client struct {
+	// url the app is served at, ex: http://users:8000
+	BaseURL string
+	// http.DefaultClient if nil
+	HTTPClient *http.Client
+	// sent with every request, ex: an Authorization header
+	Header http.Header
+}
+
+// Do sends the request and returns the response if its status is a 2xx, any other status
+// is returned as a *Problem read from the response, body is sent as json unless nil
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode the request body due to err: %v", err)
+		}
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, ReadProblem(resp)
+	}
+	return resp, nil
+}
+
+// max bytes of a response body which is not a problem document taken as the detail of the problem
+const maxProblemDetail = 1 << 10
+
+// ReadProblem reads the problem responded by an app, the body of a response which is not
+// a problem document becomes the detail of the problem
+func ReadProblem(resp *http.Response) *Problem {
+	problem := NewProblem(resp.StatusCode, "")
+	if resp.Request != nil && resp.Request.URL != nil {
+		problem.Instance = resp.Request.URL.Path
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == ContentTypeProblemJSON {
+		decoded := &Problem{}
+		if err := json.NewDecoder(resp.Body).Decode(decoded); err == nil {
+			if decoded.Status == 0 {
+				decoded.Status = resp.StatusCode
+			}
+			return decoded
+		}
+		return problem
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxProblemDetail))
+	problem.Detail = strings.TrimSpace(string(detail))
+	return problem
+}
+
+// Error makes a problem responded by an app an error for its clients
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return fmt.Sprintf("%v %v", p.Status, p.Title)
+	}
+	return fmt.Sprintf("%v %v: %v", p.Status, p.Title, p.Detail)
+}
+
+// StatusCode makes a problem returned by a client respond with the same status when returned by a handler
+func (p *Problem) StatusCode() int {
+	return p.Status
+}
+
+// FormatParam formats the value of a path or query param the way the generated app parses it,
+// a string, or a value of a type defined as a string, as is and anything else as json
+func FormatParam(v any) string {
+	if value := reflect.ValueOf(v); value.Kind() == reflect.String {
+		return value.String()
+	}
+	valueBytes, _ := json.Marshal(v)
+	return string(valueBytes)
+}
+
+// DecodeJSON decodes the body of the response into v and closes it
+func DecodeJSON(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+	err := json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("unable to decode the response due to err: %v", err)
+	}
+	return nil
+}
+
+// ReadBody reads the body of the response and closes it
+func ReadBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// EscapeCatchAll escapes the value of a catch-all param segment by segment, the leading '/' of
+// the value is left out as the path has it, ex: "/a b/c" for /files/*name becomes "a%20b/c"
+func EscapeCatchAll(value string) string {
+	segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,69 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ondbyte/matte/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientDo(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/users/a%20b":
+			assert.Equal("a b", r.URL.Query().Get("name"))
+			assert.Equal("secret", r.Header.Get("Authorization"))
+			assert.Equal("application/json", r.Header.Get("Content-Type"))
+			body := map[string]int{}
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(body["age"])
+		case "/missing":
+			web.WriteError(w, r, web.NotFound("user %v", 7))
+		default:
+			http.Error(w, "boom", http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+	c := &web.Client{BaseURL: server.URL + "/", Header: http.Header{"Authorization": {"secret"}}}
+
+	resp, err := c.Do(context.Background(), "POST", "/users/"+url.PathEscape("a b"), url.Values{"name": {"a b"}}, map[string]int{"age": 3})
+	if assert.NoError(err) {
+		age := 0
+		assert.NoError(web.DecodeJSON(resp, &age))
+		assert.Equal(3, age)
+	}
+
+	_, err = c.Do(context.Background(), "GET", "/missing", nil, nil)
+	var problem *web.Problem
+	if assert.ErrorAs(err, &problem) {
+		assert.Equal(http.StatusNotFound, problem.Status)
+		assert.Equal("user 7", problem.Detail)
+		assert.Equal("/missing", problem.Instance)
+		assert.Equal(http.StatusNotFound, web.StatusOf(err))
+		assert.Equal("404 Not Found: user 7", err.Error())
+	}
+
+	_, err = c.Do(context.Background(), "GET", "/other", nil, nil)
+	if assert.ErrorAs(err, &problem) {
+		assert.Equal(http.StatusBadGateway, problem.Status)
+		assert.Equal("boom", problem.Detail)
+	}
+	assert.False(errors.Is(err, web.ErrNotFound))
+}
+
+func TestFormatParam(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("a b", web.FormatParam("a b"))
+	assert.Equal("42", web.FormatParam(42))
+	assert.Equal("true", web.FormatParam(true))
+	type id string
+	assert.Equal("abc", web.FormatParam(id("abc")))
+	assert.Equal("a%20b/c", web.EscapeCatchAll("/a b/c"))
+}
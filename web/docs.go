@@ -0,0 +1,62 @@
+package web
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"os"
+)
+
+// EnvVar is the environment variable telling the environment the app runs in
+const EnvVar = "MATTE_ENV"
+
+// Env returns the environment the app runs in, "production" unless EnvVar says otherwise,
+// so a deploy which does not set it does not serve what is enabled only in development
+func Env() string {
+	if env := os.Getenv(EnvVar); env != "" {
+		return env
+	}
+	return "production"
+}
+
+// EnabledIn reports whether the app runs in one of the environments, no environments means every environment
+func EnabledIn(environments ...string) bool {
+	if len(environments) == 0 {
+		return true
+	}
+	env := Env()
+	for _, e := range environments {
+		if e == env {
+			return true
+		}
+	}
+	return false
+}
+
+// SpecHandler serves the openapi document
+func SpecHandler(spec []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+}
+
+//go:embed docs/index.html
+var docsHTML string
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsHTML))
+
+// DocsHandler serves the api explorer for the openapi document at specPath,
+// everything it needs is compiled into the app so it works offline
+func DocsHandler(specPath string) http.Handler {
+	page := &bytes.Buffer{}
+	err := docsTemplate.Execute(page, struct{ SpecPath string }{specPath})
+	if err != nil {
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	})
+}
@@ -0,0 +1,43 @@
+package web_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ondbyte/matte/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocsHandlerIsSelfContained(t *testing.T) {
+	assert := assert.New(t)
+	rec := httptest.NewRecorder()
+	web.DocsHandler("/api/openapi.json").ServeHTTP(rec, httptest.NewRequest("GET", "/docs", nil))
+	assert.Equal(200, rec.Code)
+	assert.Contains(rec.Body.String(), `var specPath = "/api/openapi.json";`)
+	assert.NotContains(rec.Body.String(), "https://")
+	assert.NotContains(rec.Body.String(), "http://")
+}
+
+func TestEnabledIn(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv(web.EnvVar, "")
+	assert.Equal("production", web.Env())
+	assert.False(web.EnabledIn("development", "staging"))
+	assert.True(web.EnabledIn())
+	t.Setenv(web.EnvVar, "development")
+	assert.True(web.EnabledIn("development", "staging"))
+	t.Setenv(web.EnvVar, "production")
+	assert.False(web.EnabledIn("development", "staging"))
+	assert.True(web.EnabledIn())
+}
+
+func TestVersionHandler(t *testing.T) {
+	assert := assert.New(t)
+	v := web.NewVersion("users", "1.2.0")
+	v.Revision = "5f3c2a1b9e"
+	assert.Equal("users 1.2.0 (5f3c2a1)", v.String())
+	rec := httptest.NewRecorder()
+	web.VersionHandler(v).ServeHTTP(rec, httptest.NewRequest("GET", "/version", nil))
+	assert.Equal("application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(`{"title":"users","version":"1.2.0","revision":"5f3c2a1b9e","goVersion":"`+v.GoVersion+`"}`, rec.Body.String())
+}
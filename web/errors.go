@@ -0,0 +1,178 @@
+// Package web is the runtime of the apps generated by matte,
+// handlers can return its errors to control the http response they result in.
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// StatusCoder is implemented by errors which know the http status they should be responded with
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Error is an error with a http status
+type Error struct {
+	Status  int
+	Message string
+	// the error which caused this one, if any
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same status,
+// so errors.Is(web.NotFound("user %v", id), web.ErrNotFound) is true
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == nil && t.Status == e.Status
+}
+
+func NewError(status int, message string) *Error {
+	return &Error{Status: status, Message: message}
+}
+
+// Errorf returns an error with the status, an error passed for %w becomes its cause
+func Errorf(status int, format string, args ...any) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{Status: status, Message: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// sentinel errors, handlers can return them as is or wrapped
+var (
+	ErrBadRequest          = NewError(http.StatusBadRequest, "bad request")
+	ErrUnauthorized        = NewError(http.StatusUnauthorized, "unauthorized")
+	ErrForbidden           = NewError(http.StatusForbidden, "forbidden")
+	ErrNotFound            = NewError(http.StatusNotFound, "not found")
+	ErrConflict            = NewError(http.StatusConflict, "conflict")
+	ErrGone                = NewError(http.StatusGone, "gone")
+	ErrUnprocessableEntity = NewError(http.StatusUnprocessableEntity, "unprocessable entity")
+	ErrTooManyRequests     = NewError(http.StatusTooManyRequests, "too many requests")
+	ErrNotImplemented      = NewError(http.StatusNotImplemented, "not implemented")
+	ErrUnavailable         = NewError(http.StatusServiceUnavailable, "service unavailable")
+)
+
+func NotFound(format string, args ...any) *Error {
+	return Errorf(http.StatusNotFound, format, args...)
+}
+
+func Conflict(format string, args ...any) *Error {
+	return Errorf(http.StatusConflict, format, args...)
+}
+
+func BadRequest(format string, args ...any) *Error {
+	return Errorf(http.StatusBadRequest, format, args...)
+}
+
+type sentinelStatus struct {
+	err    error
+	status int
+}
+
+var (
+	sentinelsMu sync.RWMutex
+	// errors of the std lib which have an obvious status
+	sentinels = []sentinelStatus{
+		{fs.ErrNotExist, http.StatusNotFound},
+		{sql.ErrNoRows, http.StatusNotFound},
+		{fs.ErrPermission, http.StatusForbidden},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+	}
+)
+
+// RegisterStatus makes every error matching err (using errors.Is) respond with the status,
+// call it from an init func of your handlers pkg to map your own sentinel errors,
+// ex: web.RegisterStatus(repo.ErrDuplicateEmail, http.StatusConflict)
+func RegisterStatus(err error, status int) {
+	sentinelsMu.Lock()
+	defer sentinelsMu.Unlock()
+	// latest registration wins
+	sentinels = append([]sentinelStatus{{err, status}}, sentinels...)
+}
+
+// StatusOf returns the http status for the err, a StatusCoder in the chain of err decides it,
+// then the registered sentinel errors, 500 otherwise
+func StatusOf(err error) int {
+	status, _ := statusOf(err)
+	return status
+}
+
+// returns the status for the err along with the message of the error deciding it, ie: the Message of a *Error,
+// the message of a StatusCoder or of a registered sentinel error, so the context err is wrapped in is left out
+func statusOf(err error) (int, string) {
+	var statusCoder StatusCoder
+	if errors.As(err, &statusCoder) {
+		if e, ok := statusCoder.(*Error); ok {
+			return e.Status, e.Message
+		}
+		return statusCoder.StatusCode(), statusCoder.(error).Error()
+	}
+	sentinelsMu.RLock()
+	defer sentinelsMu.RUnlock()
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			return s.status, s.err.Error()
+		}
+	}
+	return http.StatusInternalServerError, ""
+}
+
+// ErrorLogger logs an error responded with a server error status
+type ErrorLogger func(r *http.Request, err error)
+
+var (
+	errorLoggerMu sync.RWMutex
+	errorLogger   ErrorLogger = LogError
+)
+
+// SetErrorLogger replaces the logger of the server errors of the app, ex: to report them to your error tracker,
+// call it from an init func of your handlers pkg
+func SetErrorLogger(logger ErrorLogger) {
+	errorLoggerMu.Lock()
+	defer errorLoggerMu.Unlock()
+	if logger == nil {
+		logger = LogError
+	}
+	errorLogger = logger
+}
+
+// LogError is the default ErrorLogger, it logs the err along with the request using the log pkg
+func LogError(r *http.Request, err error) {
+	log.Printf("%v %v: %v", r.Method, r.URL.Path, err)
+}
+
+// WriteError responds with a Problem having the status of err, the detail of a client error is the message of
+// the error deciding its status, ex: "not found" for fmt.Errorf("loading user: %w", web.ErrNotFound), return
+// a *Error, ex: web.NotFound("user %v", id), to tell the client more. a server error is logged using the ErrorLogger
+// and its message is not exposed to the client
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status, detail := statusOf(err)
+	if status >= http.StatusInternalServerError {
+		detail = ""
+		errorLoggerMu.RLock()
+		logger := errorLogger
+		errorLoggerMu.RUnlock()
+		logger(r, err)
+	}
+	WriteProblem(w, r, NewProblem(status, detail))
+}
@@ -0,0 +1,64 @@
+package web_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ondbyte/matte/web"
+	"github.com/stretchr/testify/assert"
+)
+
+type teapotErr struct{}
+
+func (teapotErr) Error() string   { return "i am a teapot" }
+func (teapotErr) StatusCode() int { return http.StatusTeapot }
+
+func TestStatusOf(t *testing.T) {
+	assert := assert.New(t)
+	errDuplicate := errors.New("duplicate")
+	web.RegisterStatus(errDuplicate, http.StatusConflict)
+
+	assert.Equal(http.StatusNotFound, web.StatusOf(web.ErrNotFound))
+	assert.Equal(http.StatusNotFound, web.StatusOf(web.NotFound("user %v", 1)))
+	assert.Equal(http.StatusNotFound, web.StatusOf(fmt.Errorf("loading user: %w", sql.ErrNoRows)))
+	assert.Equal(http.StatusTeapot, web.StatusOf(fmt.Errorf("wrapped: %w", teapotErr{})))
+	assert.Equal(http.StatusConflict, web.StatusOf(fmt.Errorf("saving: %w", errDuplicate)))
+	assert.Equal(http.StatusInternalServerError, web.StatusOf(errors.New("boom")))
+	assert.True(errors.Is(web.Conflict("email %v", "a@b.c"), web.ErrConflict))
+}
+
+func TestWriteErrorHidesServerErrors(t *testing.T) {
+	assert := assert.New(t)
+	rec := httptest.NewRecorder()
+	var logged error
+	web.SetErrorLogger(func(r *http.Request, err error) { logged = err })
+	defer web.SetErrorLogger(nil)
+	web.WriteError(rec, httptest.NewRequest("GET", "/", nil), errors.New("db password is hunter2"))
+	assert.Equal(http.StatusInternalServerError, rec.Code)
+	assert.NotContains(rec.Body.String(), "hunter2")
+	assert.EqualError(logged, "db password is hunter2")
+}
+
+func TestWriteErrorDetail(t *testing.T) {
+	for _, c := range []struct {
+		err    error
+		detail string
+	}{
+		{fmt.Errorf("loading user 7 from shard-3: %w", web.ErrNotFound), "not found"},
+		{fmt.Errorf("loading user 7 from shard-3: %w", sql.ErrNoRows), sql.ErrNoRows.Error()},
+		{fmt.Errorf("brewing: %w", teapotErr{}), "i am a teapot"},
+		{fmt.Errorf("handler: %w", web.NotFound("user %v", 7)), "user 7"},
+	} {
+		rec := httptest.NewRecorder()
+		web.WriteError(rec, httptest.NewRequest("GET", "/", nil), c.err)
+		var problem web.Problem
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem), c.err.Error()) {
+			assert.Equal(t, c.detail, problem.Detail, c.err.Error())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+const ContentTypeProblemJSON = "application/problem+json"
+
+// InvalidParam tells why a param of the request is invalid
+type InvalidParam struct {
+	Name string `json:"name"`
+	// part of the request the param is read from, ex: path, query or body
+	In     string `json:"in"`
+	Reason string `json:"reason"`
+}
+
+// Problem is a problem details document as described by RFC 7807
+type Problem struct {
+	Type          string         `json:"type,omitempty"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ProblemRenderer writes the problem into the response
+type ProblemRenderer func(w http.ResponseWriter, r *http.Request, problem *Problem)
+
+var (
+	rendererMu      sync.RWMutex
+	problemRenderer ProblemRenderer = RenderProblemJSON
+)
+
+// SetProblemRenderer replaces the renderer used for every error response of the app,
+// call it from an init func of your handlers pkg to respond with your own error envelope
+func SetProblemRenderer(renderer ProblemRenderer) {
+	rendererMu.Lock()
+	defer rendererMu.Unlock()
+	if renderer == nil {
+		renderer = RenderProblemJSON
+	}
+	problemRenderer = renderer
+}
+
+// RenderProblemJSON is the default ProblemRenderer, it writes the problem as application/problem+json
+func RenderProblemJSON(w http.ResponseWriter, r *http.Request, problem *Problem) {
+	if problem.Instance == "" && r != nil && r.URL != nil {
+		problem.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", ContentTypeProblemJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// WriteProblem responds with the problem using the current ProblemRenderer
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem *Problem) {
+	rendererMu.RLock()
+	renderer := problemRenderer
+	rendererMu.RUnlock()
+	renderer(w, r, problem)
+}
+
+// WriteValidationProblem responds with 400 listing every invalid param of the request
+func WriteValidationProblem(w http.ResponseWriter, r *http.Request, invalidParams []InvalidParam) {
+	problem := NewProblem(http.StatusBadRequest, "request has invalid params")
+	problem.InvalidParams = invalidParams
+	WriteProblem(w, r, problem)
+}
@@ -0,0 +1,41 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ondbyte/matte/web"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteValidationProblem(t *testing.T) {
+	assert := assert.New(t)
+	rec := httptest.NewRecorder()
+	web.WriteValidationProblem(rec, httptest.NewRequest("GET", "/users/x", nil), []web.InvalidParam{
+		{Name: "id", In: "path", Reason: "cannot be parsed as int"},
+		{Name: "limit", In: "query", Reason: "is required"},
+	})
+	assert.Equal(http.StatusBadRequest, rec.Code)
+	assert.Equal(web.ContentTypeProblemJSON, rec.Header().Get("Content-Type"))
+	problem := &web.Problem{}
+	if assert.NoError(json.Unmarshal(rec.Body.Bytes(), problem)) {
+		assert.Equal("/users/x", problem.Instance)
+		assert.Len(problem.InvalidParams, 2)
+		assert.Equal("limit", problem.InvalidParams[1].Name)
+	}
+}
+
+func TestSetProblemRenderer(t *testing.T) {
+	assert := assert.New(t)
+	web.SetProblemRenderer(func(w http.ResponseWriter, r *http.Request, problem *web.Problem) {
+		w.WriteHeader(problem.Status)
+		json.NewEncoder(w).Encode(map[string]any{"error": problem.Title})
+	})
+	defer web.SetProblemRenderer(nil)
+	rec := httptest.NewRecorder()
+	web.WriteError(rec, httptest.NewRequest("GET", "/", nil), web.ErrNotFound)
+	assert.Equal(http.StatusNotFound, rec.Code)
+	assert.JSONEq(`{"error":"Not Found"}`, rec.Body.String())
+}
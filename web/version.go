@@ -0,0 +1,55 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version of the app as served by VersionHandler
+type Version struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+	// vcs revision the app was built from, if the build info has it
+	Revision  string `json:"revision,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// NewVersion returns the version of the app, the revision is read from the build info of the binary
+func NewVersion(title, version string) Version {
+	v := Version{Title: title, Version: version, GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				v.Revision = setting.Value
+			}
+		}
+	}
+	return v
+}
+
+// String returns the version as shown in the banner of the app, ex: users 1.0.0 (5f3c2a1)
+func (v Version) String() string {
+	s := v.Title + " " + v.Version
+	if v.Revision != "" {
+		revision := v.Revision
+		if len(revision) > 7 {
+			revision = revision[:7]
+		}
+		s += " (" + revision + ")"
+	}
+	return s
+}
+
+// VersionHandler serves the version as json
+func VersionHandler(v Version) http.Handler {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}